@@ -4,6 +4,7 @@ package errors
 
 import (
 	"fmt"
+	"io"
 	"testing"
 
 	stderrors "errors"
@@ -108,3 +109,34 @@ func BenchmarkStackFormatting(b *testing.B) {
 	}
 	GlobalE = stackStr
 }
+
+func BenchmarkLazy(b *testing.B) {
+	type run struct {
+		name string
+		f    func(i int) error
+	}
+	req := struct {
+		Method, URL string
+		Header      map[string]string
+	}{"GET", "http://example.com/", map[string]string{"Accept": "text/plain"}}
+	runs := []run{
+		{"Errorf", func(i int) error { return Errorf("request %d failed: %+v", i, req) }},
+		{"LazyErrorf", func(i int) error { return LazyErrorf("request %d failed: %+v", i, req) }},
+		{"Wrapf", func(i int) error { return Wrapf(io.EOF, "request %d failed: %+v", i, req) }},
+		{"LazyWrapf", func(i int) error { return LazyWrapf(io.EOF, "request %d failed: %+v", i, req) }},
+	}
+	for _, r := range runs {
+		b.Run(r.name, func(b *testing.B) {
+			var err error
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				err = r.f(i)
+				if Is(err, io.ErrUnexpectedEOF) {
+					b.Fatal("unexpected match")
+				}
+			}
+			b.StopTimer()
+			GlobalE = err
+		})
+	}
+}
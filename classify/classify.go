@@ -0,0 +1,229 @@
+// Package classify records and reports whether the operation that failed
+// with an error may succeed if it is retried.
+//
+// An error is marked with its Class where the failure is understood:
+//
+//	if resp.StatusCode == http.StatusTooManyRequests {
+//		return classify.Throttled(errors.New("rate limited"), retryAfter(resp))
+//	}
+//
+// and the Class is queried where the decision to retry is made:
+//
+//	if classify.IsRetryable(err) {
+//		...
+//	}
+//
+// Errors that have not been marked are classified by what they are: a
+// context.Canceled error is permanent and context.DeadlineExceeded a
+// timeout, as is any error whose Timeout method, such as that of
+// net.Error, returns true. An error whose Temporary method returns true,
+// and the system call errors of failed connections, such as
+// syscall.ECONNRESET and syscall.ECONNREFUSED, are retryable, and an error
+// with a RetryAfter() time.Duration method is throttled.
+//
+// When the layers of an error disagree, a Class marked on the error takes
+// precedence over one found from what it is, whatever their depths. Among
+// the marked layers, or the others if none is marked, the outermost one
+// decides, as the code that wrapped an error last knows most about it. If
+// a joined error's causes at the same depth disagree, the most cautious
+// Class decides: Permanent, then Throttled, Timeout and Retryable.
+package classify
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/pkg/errors/internal/printf"
+)
+
+// Class describes whether an operation that failed may succeed if it is
+// retried. Classes other than UnknownClass are ordered by precedence, from
+// least to most cautious.
+type Class int
+
+const (
+	// UnknownClass is the Class of an error that is not known to be
+	// either retryable or permanent.
+	UnknownClass Class = iota
+
+	// RetryableClass is the Class of a transient failure, such as a reset
+	// connection, that may not recur.
+	RetryableClass
+
+	// TimeoutClass is the Class of an operation that did not complete in
+	// time. It may be retried, perhaps with a longer deadline.
+	TimeoutClass
+
+	// ThrottledClass is the Class of an operation refused because of the
+	// rate at which it was attempted. It may be retried after a delay.
+	ThrottledClass
+
+	// PermanentClass is the Class of a failure that will recur however
+	// often the operation is retried.
+	PermanentClass
+)
+
+func (c Class) String() string {
+	switch c {
+	case UnknownClass:
+		return "unknown"
+	case RetryableClass:
+		return "retryable"
+	case TimeoutClass:
+		return "timeout"
+	case ThrottledClass:
+		return "throttled"
+	case PermanentClass:
+		return "permanent"
+	}
+	return "invalid"
+}
+
+// Retryable marks err as a transient failure that may not recur.
+// If err is nil, Retryable returns nil.
+func Retryable(err error) error { return mark(err, RetryableClass, 0) }
+
+// Permanent marks err as a failure that will recur however often the
+// operation is retried.
+// If err is nil, Permanent returns nil.
+func Permanent(err error) error { return mark(err, PermanentClass, 0) }
+
+// Timeout marks err as the failure of an operation to complete in time.
+// If err is nil, Timeout returns nil.
+func Timeout(err error) error { return mark(err, TimeoutClass, 0) }
+
+// Throttled marks err as the refusal of an operation because of the rate
+// at which it was attempted, which may be retried once the duration after
+// has passed. An after of zero or less means the delay is not known.
+// If err is nil, Throttled returns nil.
+func Throttled(err error, after time.Duration) error {
+	return mark(err, ThrottledClass, after)
+}
+
+func mark(err error, class Class, after time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &marked{
+		cause: err,
+		class: class,
+		after: after,
+	}
+}
+
+// marked is an error marked with its Class. It adds nothing to the
+// message or formatted output of its cause, and is an errors.Annotation so
+// that the formatters of package errors pass through it.
+type marked struct {
+	cause error
+	class Class
+	after time.Duration
+}
+
+func (m *marked) Error() string    { return m.cause.Error() }
+func (m *marked) Cause() error     { return m.cause }
+func (m *marked) Annotated() error { return m.cause }
+
+// Unwrap provides compatibility for Go 1.13 error chains.
+func (m *marked) Unwrap() error { return m.cause }
+
+func (m *marked) Format(s fmt.State, verb rune) {
+	fmt.Fprintf(s, printf.Directive(s, verb), m.cause)
+}
+
+// Of returns the Class of err, decided by the rules of precedence
+// described in the package documentation. Of returns UnknownClass if err
+// is nil or nothing in its chain is classified.
+func Of(err error) Class {
+	c, _ := classify(err)
+	return c
+}
+
+// IsRetryable reports whether the Class of err is RetryableClass,
+// TimeoutClass or ThrottledClass. An error of UnknownClass is not
+// retryable.
+func IsRetryable(err error) bool {
+	switch Of(err) {
+	case RetryableClass, TimeoutClass, ThrottledClass:
+		return true
+	}
+	return false
+}
+
+// IsPermanent reports whether the Class of err is PermanentClass.
+func IsPermanent(err error) bool { return Of(err) == PermanentClass }
+
+// IsTimeout reports whether the Class of err is TimeoutClass.
+func IsTimeout(err error) bool { return Of(err) == TimeoutClass }
+
+// IsThrottled reports whether the Class of err is ThrottledClass.
+func IsThrottled(err error) bool { return Of(err) == ThrottledClass }
+
+// RetryAfter returns how long to wait before retrying the operation that
+// failed with err, and reports whether it is known: that is, whether err
+// is of ThrottledClass with a positive delay given to Throttled or
+// returned by the RetryAfter method of the error that decided its Class.
+func RetryAfter(err error) (time.Duration, bool) {
+	c, after := classify(err)
+	return after, c == ThrottledClass && after > 0
+}
+
+// classify returns the Class of err, with the delay before a retry if the
+// Class is ThrottledClass.
+func classify(err error) (Class, time.Duration) {
+	var marks, found decision
+	errors.Layers(err)(func(l errors.Layer) bool {
+		if m, ok := l.Err.(*marked); ok {
+			marks.consider(l.Depth, m.class, m.after)
+		} else if c, after := infer(l.Err); c != UnknownClass {
+			found.consider(l.Depth, c, after)
+		}
+		return true
+	})
+	if marks.class != UnknownClass {
+		return marks.class, marks.after
+	}
+	return found.class, found.after
+}
+
+// decision is the Class decided by the layers of an error considered so
+// far.
+type decision struct {
+	class Class
+	after time.Duration
+	depth int
+}
+
+// consider updates d with the Class c of a layer at depth: if c is the
+// first, is shallower than d's, or is more cautious at the same depth.
+func (d *decision) consider(depth int, c Class, after time.Duration) {
+	if d.class == UnknownClass || depth < d.depth || depth == d.depth && c > d.class {
+		*d = decision{c, after, depth}
+	}
+}
+
+// infer returns the Class of err from what it is, without looking at its
+// causes.
+func infer(err error) (Class, time.Duration) {
+	switch err {
+	case context.Canceled:
+		return PermanentClass, 0
+	case context.DeadlineExceeded:
+		return TimeoutClass, 0
+	}
+	if e, ok := err.(interface{ RetryAfter() time.Duration }); ok {
+		return ThrottledClass, e.RetryAfter()
+	}
+	if e, ok := err.(interface{ Timeout() bool }); ok && e.Timeout() {
+		return TimeoutClass, 0
+	}
+	if transient(err) {
+		return RetryableClass, 0
+	}
+	if e, ok := err.(interface{ Temporary() bool }); ok && e.Temporary() {
+		return RetryableClass, 0
+	}
+	return UnknownClass, 0
+}
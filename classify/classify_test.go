@@ -0,0 +1,211 @@
+package classify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"regexp"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// joined is an error with several causes, as returned by errors.Join.
+type joined []error
+
+func (j joined) Error() string   { return fmt.Sprint([]error(j)) }
+func (j joined) Unwrap() []error { return j }
+
+// rateLimited is an error with a RetryAfter method.
+type rateLimited struct{ after time.Duration }
+
+func (rateLimited) Error() string               { return "rate limited" }
+func (r rateLimited) RetryAfter() time.Duration { return r.after }
+
+func TestMarkNil(t *testing.T) {
+	for _, f := range []func(error) error{Retryable, Permanent, Timeout, func(err error) error { return Throttled(err, time.Second) }} {
+		if got := f(nil); got != nil {
+			t.Errorf("mark of nil: got %#v, expected nil", got)
+		}
+	}
+}
+
+func TestOf(t *testing.T) {
+	timeout := &net.OpError{Op: "dial", Net: "tcp", Err: os.ErrDeadlineExceeded}
+	reset := &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+	tests := []struct {
+		err  error
+		want Class
+	}{
+		{nil, UnknownClass},
+		{io.EOF, UnknownClass},
+		{Retryable(io.EOF), RetryableClass},
+		{errors.Wrap(Permanent(io.EOF), "read"), PermanentClass},
+		{Timeout(errors.New("slow")), TimeoutClass},
+		{Throttled(io.EOF, time.Second), ThrottledClass},
+		{errors.Wrap(context.Canceled, "query"), PermanentClass},
+		{fmt.Errorf("query: %w", context.DeadlineExceeded), TimeoutClass},
+		{errors.WithStack(timeout), TimeoutClass},
+		{reset, RetryableClass},
+		{errors.Wrap(syscall.ECONNREFUSED, "dial"), RetryableClass},
+		{rateLimited{time.Minute}, ThrottledClass},
+
+		// The outermost mark decides.
+		{Permanent(errors.Wrap(Retryable(io.EOF), "read")), PermanentClass},
+		{Retryable(errors.Wrap(Permanent(io.EOF), "read")), RetryableClass},
+
+		// A mark decides over what an error is, however deep.
+		{errors.WithMessage(Retryable(context.Canceled), "query"), RetryableClass},
+		{Timeout(joined{context.Canceled, io.EOF}), TimeoutClass},
+
+		// The outermost error that is classified decides.
+		{&net.OpError{Op: "dial", Err: context.Canceled}, PermanentClass},
+
+		// The most cautious of the causes at the same depth decides.
+		{joined{Retryable(io.EOF), Permanent(io.EOF), Timeout(io.EOF)}, PermanentClass},
+		{joined{Retryable(io.EOF), Timeout(io.EOF)}, TimeoutClass},
+		{joined{Retryable(io.EOF), errors.WithStack(Permanent(io.EOF))}, RetryableClass},
+		{joined{io.EOF, reset}, RetryableClass},
+	}
+
+	for i, tt := range tests {
+		if got := Of(tt.err); got != tt.want {
+			t.Errorf("test %d: Of(%v): got %v, want %v", i+1, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestQueries(t *testing.T) {
+	tests := []struct {
+		err                                      error
+		retryable, permanent, timeout, throttled bool
+	}{
+		{io.EOF, false, false, false, false},
+		{Retryable(io.EOF), true, false, false, false},
+		{Permanent(io.EOF), false, true, false, false},
+		{Timeout(io.EOF), true, false, true, false},
+		{Throttled(io.EOF, 0), true, false, false, true},
+	}
+
+	for i, tt := range tests {
+		if got := IsRetryable(tt.err); got != tt.retryable {
+			t.Errorf("test %d: IsRetryable(%v): got %v, want %v", i+1, tt.err, got, tt.retryable)
+		}
+		if got := IsPermanent(tt.err); got != tt.permanent {
+			t.Errorf("test %d: IsPermanent(%v): got %v, want %v", i+1, tt.err, got, tt.permanent)
+		}
+		if got := IsTimeout(tt.err); got != tt.timeout {
+			t.Errorf("test %d: IsTimeout(%v): got %v, want %v", i+1, tt.err, got, tt.timeout)
+		}
+		if got := IsThrottled(tt.err); got != tt.throttled {
+			t.Errorf("test %d: IsThrottled(%v): got %v, want %v", i+1, tt.err, got, tt.throttled)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		err   error
+		after time.Duration
+		ok    bool
+	}{
+		{io.EOF, 0, false},
+		{Throttled(io.EOF, 0), 0, false},
+		{errors.Wrap(Throttled(io.EOF, time.Second), "put"), time.Second, true},
+		{Throttled(Throttled(io.EOF, time.Second), time.Minute), time.Minute, true},
+		{Retryable(Throttled(io.EOF, time.Second)), 0, false},
+		{errors.WithStack(rateLimited{time.Minute}), time.Minute, true},
+		{Throttled(rateLimited{time.Minute}, 0), 0, false},
+	}
+
+	for i, tt := range tests {
+		after, ok := RetryAfter(tt.err)
+		if after != tt.after || ok != tt.ok {
+			t.Errorf("test %d: RetryAfter(%v): got %v, %v, want %v, %v", i+1, tt.err, after, ok, tt.after, tt.ok)
+		}
+	}
+}
+
+func TestMarkedError(t *testing.T) {
+	err := errors.New("whoops")
+	m := Retryable(err)
+	if got, want := m.Error(), "whoops"; got != want {
+		t.Errorf("Error(): got %q, want %q", got, want)
+	}
+	if !errors.Is(errors.Wrap(m, "outer"), err) {
+		t.Errorf("errors.Is(Wrap(Retryable(err)), err): got false, want true")
+	}
+	for _, format := range []string{"%s", "%v", "%q", "%+v"} {
+		if got, want := fmt.Sprintf(format, m), fmt.Sprintf(format, err); got != want {
+			t.Errorf("fmt.Sprintf(%q, Retryable(err)): got %q, want %q", format, got, want)
+		}
+	}
+}
+
+func TestMarkedFormat(t *testing.T) {
+	err := errors.Wrap(Retryable(errors.New("x")), "y")
+	tests := []struct {
+		f      errors.Formatter
+		format string
+		want   string
+	}{{
+		errors.CompactFormatter{},
+		"%+v",
+		`^y \(classify_test.go:150\): x \(classify_test.go:150\)$`,
+	}, {
+		errors.JSONFormatter{},
+		"%+v",
+		`^{"message":"y","stack":\[.+\]}` + "\n" +
+			`{"message":"x","stack":\[.+\]}$`,
+	}, {
+		errors.TextFormatter{Order: errors.OutermostFirst},
+		"%+.1v",
+		"^y\n" +
+			"github.com/pkg/errors/classify.TestMarkedFormat\n" +
+			"\t.+/github.com/pkg/errors/classify/classify_test.go:150\n" +
+			"x\n" +
+			"github.com/pkg/errors/classify.TestMarkedFormat\n" +
+			"\t.+/github.com/pkg/errors/classify/classify_test.go:150$",
+	}}
+
+	for i, tt := range tests {
+		got := fmt.Sprintf(tt.format, errors.Format(err, tt.f))
+		if !regexp.MustCompile(tt.want).MatchString(got) {
+			t.Errorf("test %d: fmt.Sprintf(%q, err):\n got: %q\nwant: %q", i+1, tt.format, got, tt.want)
+		}
+	}
+
+	var kinds []errors.LayerKind
+	errors.Layers(err)(func(l errors.Layer) bool {
+		kinds = append(kinds, l.Kind)
+		return true
+	})
+	want := []errors.LayerKind{errors.StackLayer, errors.MessageLayer, errors.AnnotationLayer, errors.FundamentalLayer}
+	if fmt.Sprint(kinds) != fmt.Sprint(want) {
+		t.Errorf("Layers(err): got kinds %v, want %v", kinds, want)
+	}
+}
+
+func TestClassString(t *testing.T) {
+	tests := []struct {
+		c    Class
+		want string
+	}{
+		{UnknownClass, "unknown"},
+		{RetryableClass, "retryable"},
+		{TimeoutClass, "timeout"},
+		{ThrottledClass, "throttled"},
+		{PermanentClass, "permanent"},
+		{Class(-1), "invalid"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.c.String(); got != tt.want {
+			t.Errorf("Class(%d).String(): got %q, want %q", int(tt.c), got, tt.want)
+		}
+	}
+}
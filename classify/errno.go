@@ -0,0 +1,18 @@
+//go:build !plan9 && !windows
+// +build !plan9,!windows
+
+package classify
+
+import "syscall"
+
+// transient reports whether err is a system call error of a connection
+// that failed in a way that may not recur.
+func transient(err error) bool {
+	switch err {
+	case syscall.ECONNRESET, syscall.ECONNREFUSED, syscall.ECONNABORTED,
+		syscall.EPIPE, syscall.ENETUNREACH, syscall.EHOSTUNREACH,
+		syscall.ENETDOWN, syscall.ENETRESET:
+		return true
+	}
+	return false
+}
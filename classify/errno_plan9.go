@@ -0,0 +1,6 @@
+package classify
+
+// transient reports whether err is a system call error of a connection
+// that failed in a way that may not recur. Plan 9 reports such errors as
+// strings, which are not classified.
+func transient(err error) bool { return false }
@@ -0,0 +1,30 @@
+package classify
+
+import "syscall"
+
+// Winsock errors that the syscall package does not define.
+const (
+	wsaENETDOWN     syscall.Errno = 10050
+	wsaENETUNREACH  syscall.Errno = 10051
+	wsaENETRESET    syscall.Errno = 10052
+	wsaECONNREFUSED syscall.Errno = 10061
+	wsaEHOSTUNREACH syscall.Errno = 10065
+)
+
+// transient reports whether err is a system call error of a connection
+// that failed in a way that may not recur. The net package reports such
+// errors as Winsock codes, such as WSAECONNRESET, rather than as the
+// Errno values that the syscall package defines for other systems, which
+// are matched too.
+func transient(err error) bool {
+	switch err {
+	case syscall.WSAECONNRESET, syscall.WSAECONNABORTED, wsaECONNREFUSED,
+		wsaENETUNREACH, wsaEHOSTUNREACH, wsaENETDOWN, wsaENETRESET,
+		syscall.ERROR_BROKEN_PIPE,
+		syscall.ECONNRESET, syscall.ECONNREFUSED, syscall.ECONNABORTED,
+		syscall.EPIPE, syscall.ENETUNREACH, syscall.EHOSTUNREACH,
+		syscall.ENETDOWN, syscall.ENETRESET:
+		return true
+	}
+	return false
+}
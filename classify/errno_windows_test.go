@@ -0,0 +1,24 @@
+package classify
+
+import (
+	"net"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestWinsockErrors(t *testing.T) {
+	errs := []syscall.Errno{
+		syscall.WSAECONNRESET,
+		syscall.WSAECONNABORTED,
+		wsaECONNREFUSED,
+		wsaENETUNREACH,
+		wsaEHOSTUNREACH,
+	}
+	for _, errno := range errs {
+		err := &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("wsarecv", errno)}
+		if !IsRetryable(err) {
+			t.Errorf("IsRetryable(%v): got false, want true", err)
+		}
+	}
+}
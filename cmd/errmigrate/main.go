@@ -0,0 +1,149 @@
+// Command errmigrate rewrites Go source files between the error handling of
+// github.com/pkg/errors and that of the standard library.
+//
+// Usage:
+//
+//	errmigrate -to std|pkg [-w] [path ...]
+//
+// With -to std, calls of github.com/pkg/errors are rewritten to the
+// standard library: Wrap(err, "x") becomes fmt.Errorf("x: %w", err), as do
+// Wrapf, WithMessage and WithMessagef; WithStack(err) becomes err; Errorf
+// becomes fmt.Errorf; and a type switch on Cause(err) becomes a chain of
+// if statements calling errors.As. When no other function of the package
+// is left, its import is replaced by the standard errors package.
+//
+// With -to pkg, a call fmt.Errorf("x: %w", err) that wraps err at the end
+// of its message is rewritten to Wrap(err, "x"), or to Wrapf if the message
+// formats other arguments, and an import of the standard errors package is
+// replaced by github.com/pkg/errors when the functions it uses exist there.
+//
+// Where a rewrite changes what the program does, such as by no longer
+// recording a stack trace, or where a call cannot be rewritten, errmigrate
+// reports it on standard error as file:line:column: message.
+//
+// A path may be a file or a directory, whose .go files are rewritten
+// recursively, skipping the testdata, vendor, hidden and "_" directories
+// beneath it, but not the directory itself, such as "..". Without a path,
+// standard input is rewritten. The rewritten source is formatted as by
+// gofmt and printed to standard output, unless -w is given, in which case
+// it replaces the content of each file that changes.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	to    = flag.String("to", "", "rewrite to the `style` std, of the standard library, or pkg, of github.com/pkg/errors")
+	write = flag.Bool("w", false, "write the result to the source file instead of standard output")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: errmigrate -to std|pkg [-w] [path ...]\n")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	var s style
+	switch *to {
+	case "std":
+		s = std
+	case "pkg":
+		s = pkg
+	default:
+		usage()
+	}
+
+	if flag.NArg() == 0 {
+		if *write {
+			fmt.Fprintln(os.Stderr, "errmigrate: cannot use -w with standard input")
+			os.Exit(2)
+		}
+		src, err := ioutil.ReadAll(os.Stdin)
+		if err == nil {
+			err = process("<standard input>", src, s, os.Stdout, os.Stderr)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	status := 0
+	for _, path := range flag.Args() {
+		if err := walk(path, s); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			status = 1
+		}
+	}
+	os.Exit(status)
+}
+
+// walk rewrites the file at root, or the .go files beneath it if it is a
+// directory. Directories beneath root named testdata or vendor, or whose
+// names begin with "." or "_", are skipped.
+func walk(root string, s style) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			name := info.Name()
+			if path != root && (name == "testdata" || name == "vendor" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+		src, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if !*write {
+			return process(path, src, s, os.Stdout, os.Stderr)
+		}
+		var out bytes.Buffer
+		if err := process(path, src, s, &out, os.Stderr); err != nil {
+			return err
+		}
+		if bytes.Equal(src, out.Bytes()) {
+			return nil
+		}
+		return ioutil.WriteFile(path, out.Bytes(), info.Mode().Perm())
+	})
+}
+
+// process rewrites src, the content of the named file, to s, writing the
+// result to out and the reports of the rewrite to reports.
+func process(filename string, src []byte, s style, out, reports io.Writer) error {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+	for _, r := range migrate(fset, f, s) {
+		fmt.Fprintf(reports, "%s: %s\n", fset.Position(r.pos), r.msg)
+	}
+	var b bytes.Buffer
+	if err := format.Node(&b, fset, f); err != nil {
+		return err
+	}
+	_, err = out.Write(b.Bytes())
+	return err
+}
@@ -0,0 +1,662 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors/internal/printf"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// errorsPath is the import path of github.com/pkg/errors.
+const errorsPath = "github.com/pkg/errors"
+
+// style is the error handling to which a file is rewritten.
+type style int
+
+const (
+	std style = iota // the standard library's errors and fmt.Errorf
+	pkg              // github.com/pkg/errors
+)
+
+// shared are the functions found in both the standard errors package and
+// github.com/pkg/errors, which behave alike but for the stack trace
+// recorded by the latter's New.
+var shared = map[string]bool{"New": true, "Is": true, "As": true, "Unwrap": true}
+
+// A report describes a place where a rewrite changes what the program
+// does, or where it could not be made.
+type report struct {
+	pos token.Pos
+	msg string
+}
+
+// migrate rewrites f to s, returning the reports of the rewrite in the
+// order of their positions.
+func migrate(fset *token.FileSet, f *ast.File, s style) []report {
+	m := &migrator{fset: fset, file: f, reported: make(map[ast.Node]bool)}
+	if s == std {
+		m.toStd()
+	} else {
+		m.toPkg()
+	}
+	if m.fmtUsed && importName(f, "fmt") == "" {
+		astutil.AddImport(fset, f, "fmt")
+	}
+	sort.SliceStable(m.reports, func(i, j int) bool { return m.reports[i].pos < m.reports[j].pos })
+	return m.reports
+}
+
+// migrator rewrites a file.
+type migrator struct {
+	fset     *token.FileSet
+	file     *ast.File
+	reports  []report
+	reported map[ast.Node]bool // nodes left as they were and reported
+	stack    []ast.Node        // the node being rewritten and those enclosing it
+	errors   string            // the name of github.com/pkg/errors in the file
+	fmtUsed  bool              // whether the rewrite calls a function of fmt
+}
+
+// report records a report at the position of n.
+func (m *migrator) report(n ast.Node, format string, args ...interface{}) {
+	m.reports = append(m.reports, report{n.Pos(), fmt.Sprintf(format, args...)})
+}
+
+// skip reports that n was left as it was.
+func (m *migrator) skip(n ast.Node, format string, args ...interface{}) {
+	m.reported[n] = true
+	m.report(n, format, args...)
+}
+
+// apply calls rewrite for each node of the file, after its children.
+func (m *migrator) apply(rewrite func(c *astutil.Cursor)) {
+	astutil.Apply(m.file, func(c *astutil.Cursor) bool {
+		m.stack = append(m.stack, c.Node())
+		return true
+	}, func(c *astutil.Cursor) bool {
+		rewrite(c)
+		m.stack = m.stack[:len(m.stack)-1]
+		return true
+	})
+}
+
+// toStd rewrites the file to use the standard library.
+func (m *migrator) toStd() {
+	m.errors = importName(m.file, errorsPath)
+	switch m.errors {
+	case "":
+		return
+	case ".", "_":
+		m.report(m.file.Name, "%s import of %s is not rewritten", m.errors, errorsPath)
+		return
+	}
+	m.apply(m.rewriteStd)
+
+	// Replace the import if what is left of the package is in the
+	// standard library too.
+	var left []*ast.SelectorExpr
+	replace := true
+	ast.Inspect(m.file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok && m.isPkg(sel.X, m.errors) {
+			left = append(left, sel)
+			if !shared[sel.Sel.Name] {
+				replace = false
+				if !m.reported[sel] {
+					m.skip(sel, "%s.%s has no equivalent in the standard library; not rewritten", m.errors, sel.Sel.Name)
+				}
+			}
+		}
+		return true
+	})
+	if !replace {
+		return
+	}
+	name := ""
+	for _, imp := range m.file.Imports {
+		if path, _ := strconv.Unquote(imp.Path.Value); path == errorsPath && imp.Name != nil {
+			name = imp.Name.Name
+		}
+	}
+	stdName := importName(m.file, "errors")
+	switch {
+	case len(left) == 0:
+		astutil.DeleteNamedImport(m.fset, m.file, name, errorsPath)
+	case stdName != "" && stdName != "." && stdName != "_":
+		for _, sel := range left {
+			sel.X.(*ast.Ident).Name = stdName
+		}
+		astutil.DeleteNamedImport(m.fset, m.file, name, errorsPath)
+	default:
+		astutil.DeleteNamedImport(m.fset, m.file, name, errorsPath)
+		if name == "errors" {
+			name = ""
+		}
+		astutil.AddNamedImport(m.fset, m.file, name, "errors")
+	}
+	for _, sel := range left {
+		if sel.Sel.Name == "New" {
+			m.report(sel, "errors.New of the standard library records no stack trace")
+		}
+	}
+}
+
+// rewriteStd rewrites the node at c to use the standard library.
+func (m *migrator) rewriteStd(c *astutil.Cursor) {
+	switch n := c.Node().(type) {
+	case *ast.CallExpr:
+		switch name := m.errorsFunc(n); name {
+		case "Wrap", "Wrapf", "WithMessage", "WithMessagef":
+			if call := m.wrapToStd(n, name); call != nil {
+				c.Replace(call)
+			}
+		case "WithStack":
+			if len(n.Args) == 1 {
+				m.report(n, "errors.WithStack removed; the stack trace it recorded is lost")
+				c.Replace(n.Args[0])
+			}
+		case "Errorf":
+			m.report(n, "fmt.Errorf records no stack trace")
+			n.Fun = m.fmtFunc(n.Fun, "Errorf")
+		case "Cause":
+			// The Cause of a type switch is rewritten with the switch.
+			if ta, ok := m.stack[len(m.stack)-2].(*ast.TypeAssertExpr); ok && ta.Type == nil {
+				return
+			}
+			m.skip(n.Fun, "errors.Cause has no equivalent in the standard library; not rewritten")
+		}
+	case *ast.TypeSwitchStmt:
+		if s := m.causeSwitch(n); s != nil {
+			c.Replace(s)
+		}
+	}
+}
+
+// wrapToStd returns the call of fmt.Errorf to replace call, a call of the
+// named function Wrap, Wrapf, WithMessage or WithMessagef, or nil if it
+// cannot be rewritten.
+func (m *migrator) wrapToStd(call *ast.CallExpr, name string) ast.Expr {
+	if len(call.Args) < 2 || call.Ellipsis.IsValid() {
+		m.skip(call.Fun, "errors.%s with a variadic argument is not rewritten", name)
+		return nil
+	}
+	err, msg, args := call.Args[0], call.Args[1], call.Args[2:]
+	isFormat := strings.HasSuffix(name, "f")
+	var f ast.Expr
+	if lit, ok := msg.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+		s, _ := strconv.Unquote(lit.Value)
+		if !isFormat {
+			s = strings.Replace(s, "%", "%%", -1)
+		}
+		f = &ast.BasicLit{ValuePos: lit.ValuePos, Kind: token.STRING, Value: quote(lit, s+": %w")}
+	} else {
+		f = &ast.BasicLit{ValuePos: msg.Pos(), Kind: token.STRING, Value: `"%s: %w"`}
+		if isFormat {
+			msg = &ast.CallExpr{Fun: m.fmtFunc(nil, "Sprintf"), Args: append([]ast.Expr{msg}, args...)}
+		}
+		args = []ast.Expr{msg}
+	}
+
+	if strings.HasPrefix(name, "Wrap") {
+		m.report(call, "errors.%s recorded a stack trace, which fmt.Errorf does not", name)
+	}
+	if !m.guarded(err) {
+		m.report(call, "errors.%s returned nil for a nil error, where fmt.Errorf returns an error", name)
+	}
+	return &ast.CallExpr{
+		Fun:    m.fmtFunc(call.Fun, "Errorf"),
+		Lparen: call.Lparen,
+		Args:   append(append([]ast.Expr{f}, args...), err),
+		Rparen: call.Rparen,
+	}
+}
+
+// causeSwitch returns the if statements that call errors.As to replace s,
+// a type switch on the result of errors.Cause, or nil if s is not one or
+// cannot be rewritten.
+func (m *migrator) causeSwitch(s *ast.TypeSwitchStmt) ast.Stmt {
+	var bind string
+	var ta *ast.TypeAssertExpr
+	switch a := s.Assign.(type) {
+	case *ast.AssignStmt:
+		bind = a.Lhs[0].(*ast.Ident).Name
+		ta, _ = a.Rhs[0].(*ast.TypeAssertExpr)
+	case *ast.ExprStmt:
+		ta, _ = a.X.(*ast.TypeAssertExpr)
+	}
+	if ta == nil {
+		return nil
+	}
+	call, ok := ta.X.(*ast.CallExpr)
+	if !ok || m.errorsFunc(call) != "Cause" || len(call.Args) != 1 {
+		return nil
+	}
+	err := call.Args[0]
+	fail := func(reason string) ast.Stmt {
+		m.skip(call.Fun, "type switch on errors.Cause not rewritten: %s", reason)
+		return nil
+	}
+	if s.Init != nil {
+		return fail("it has an init statement")
+	}
+	if !isVar(err) {
+		return fail("its error is not a variable")
+	}
+
+	// A case that uses bind declares it as the target of errors.As, unless
+	// bind is the variable inspected, which the target would shadow. The
+	// target then has a name of its own, assigned to bind in the case.
+	target := bind
+	if inspected := []ast.Stmt{&ast.ExprStmt{X: err}}; bind != "" && uses(inspected, bind) {
+		target = fresh(append(inspected, s.Body.List...), "target")
+	}
+
+	var ifs []*ast.IfStmt
+	var def *ast.CaseClause
+	var defEnd token.Pos
+	for i, stmt := range s.Body.List {
+		cc := stmt.(*ast.CaseClause)
+		end := s.Body.Rbrace
+		if i+1 < len(s.Body.List) {
+			end = s.Body.List[i+1].Pos()
+		}
+		if breaks(cc.Body) {
+			return fail("a case breaks out of the switch")
+		}
+		used := bind != "" && uses(cc.Body, bind)
+		if cc.List == nil {
+			if used {
+				return fail("its default case uses " + bind)
+			}
+			def = cc
+			defEnd = end
+			continue
+		}
+		if len(cc.List) > 1 && used {
+			return fail("a case of several types uses " + bind)
+		}
+		is := &ast.IfStmt{If: cc.Case, Body: &ast.BlockStmt{Lbrace: cc.Colon, List: cc.Body, Rbrace: end}}
+		pos := cc.Case
+		for _, t := range cc.List {
+			if id, ok := t.(*ast.Ident); ok && id.Name == "nil" {
+				return fail("it has a nil case")
+			}
+			var cond ast.Expr
+			if used {
+				is.Init = &ast.AssignStmt{
+					Lhs:    []ast.Expr{&ast.Ident{NamePos: pos, Name: target}},
+					TokPos: pos,
+					Tok:    token.DEFINE,
+					Rhs:    []ast.Expr{zero(t)},
+				}
+				cond = m.as(pos, err, &ast.UnaryExpr{OpPos: pos, Op: token.AND, X: &ast.Ident{NamePos: pos, Name: target}})
+			} else {
+				cond = m.as(pos, err, &ast.CallExpr{Fun: &ast.Ident{NamePos: pos, Name: "new"}, Args: []ast.Expr{t}})
+			}
+			if is.Cond == nil {
+				is.Cond = cond
+			} else {
+				is.Cond = &ast.BinaryExpr{X: is.Cond, Op: token.LOR, Y: cond}
+			}
+		}
+		if used && target != bind {
+			is.Body.List = append([]ast.Stmt{&ast.AssignStmt{
+				Lhs:    []ast.Expr{&ast.Ident{NamePos: cc.Colon, Name: bind}},
+				TokPos: cc.Colon,
+				Tok:    token.DEFINE,
+				Rhs:    []ast.Expr{&ast.Ident{NamePos: cc.Colon, Name: target}},
+			}}, is.Body.List...)
+		}
+		ifs = append(ifs, is)
+	}
+	if len(ifs) == 0 {
+		return fail("it has no case of a type")
+	}
+
+	ifs[0].If = s.Switch
+	for i := 1; i < len(ifs); i++ {
+		ifs[i-1].Else = ifs[i]
+	}
+	if def != nil && len(def.Body) > 0 {
+		ifs[len(ifs)-1].Else = &ast.BlockStmt{Lbrace: def.Colon, List: def.Body, Rbrace: defEnd}
+	}
+	m.report(s, "errors.As matches the first error of each type in the chain of %s, where errors.Cause found only the last error that has a Cause method", m.render(err))
+	return ifs[0]
+}
+
+// as returns a call at pos of errors.As.
+func (m *migrator) as(pos token.Pos, err, target ast.Expr) ast.Expr {
+	return &ast.CallExpr{
+		Fun:  &ast.SelectorExpr{X: &ast.Ident{NamePos: pos, Name: m.errors}, Sel: &ast.Ident{NamePos: pos, Name: "As"}},
+		Args: []ast.Expr{copyVar(err, pos), target},
+	}
+}
+
+// toPkg rewrites the file to use github.com/pkg/errors.
+func (m *migrator) toPkg() {
+	m.errors = importName(m.file, errorsPath)
+	if m.errors == "" || m.errors == "_" || m.errors == "." {
+		m.errors = "errors"
+		stdName := importName(m.file, "errors")
+		if stdName != "" && stdName != "_" && stdName != "." {
+			if m.usesOnlyShared(stdName) {
+				name := ""
+				if stdName != "errors" {
+					name = stdName
+				}
+				astutil.DeleteNamedImport(m.fset, m.file, name, "errors")
+				astutil.AddNamedImport(m.fset, m.file, name, errorsPath)
+				m.errors = stdName
+			} else if stdName == "errors" {
+				m.errors = "pkgerrors"
+			}
+		}
+	}
+	if importName(m.file, "fmt") == "" {
+		return
+	}
+	m.apply(m.rewritePkg)
+	if m.errorsUsed() && importName(m.file, errorsPath) == "" {
+		name := m.errors
+		if name == "errors" {
+			name = ""
+		}
+		astutil.AddNamedImport(m.fset, m.file, name, errorsPath)
+	}
+}
+
+// rewritePkg rewrites the node at c to use github.com/pkg/errors.
+func (m *migrator) rewritePkg(c *astutil.Cursor) {
+	call, ok := c.Node().(*ast.CallExpr)
+	if !ok || !m.isFunc(call, "fmt", "Errorf") || len(call.Args) == 0 {
+		return
+	}
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return
+	}
+	text, _ := strconv.Unquote(lit.Value)
+	vs, ok := printf.Parse(text)
+	if !ok {
+		return
+	}
+	var ws []printf.Verb
+	for _, v := range vs {
+		if v.Verb == 'w' {
+			ws = append(ws, v)
+		}
+	}
+	if len(ws) == 0 {
+		return
+	}
+	w := ws[0]
+	if len(ws) > 1 || w != vs[len(vs)-1] || w.End != len(text) ||
+		!strings.HasSuffix(text[:w.Start], ": ") || w.Arg != len(call.Args)-2 ||
+		call.Ellipsis.IsValid() {
+		m.skip(call, "fmt.Errorf not rewritten; errors.Cause does not look past the error it returns, which has no Cause method")
+		return
+	}
+
+	prefix := strings.TrimSuffix(text[:w.Start], ": ")
+	err := call.Args[len(call.Args)-1]
+	name := "Wrapf"
+	if len(vs) == 1 {
+		name = "Wrap"
+		prefix = strings.Replace(prefix, "%%", "%", -1)
+	}
+	args := []ast.Expr{err, &ast.BasicLit{ValuePos: lit.ValuePos, Kind: token.STRING, Value: quote(lit, prefix)}}
+	args = append(args, call.Args[1:len(call.Args)-1]...)
+	if !m.guarded(err) {
+		m.report(call, "errors.%s returns nil for a nil error, where fmt.Errorf returned an error", name)
+	}
+	c.Replace(&ast.CallExpr{
+		Fun:    &ast.SelectorExpr{X: &ast.Ident{NamePos: call.Fun.Pos(), Name: m.errors}, Sel: ast.NewIdent(name)},
+		Lparen: call.Lparen,
+		Args:   args,
+		Rparen: call.Rparen,
+	})
+}
+
+// usesOnlyShared reports whether the file uses no function of the package
+// with the given name that is not shared by both errors packages.
+func (m *migrator) usesOnlyShared(name string) bool {
+	only := true
+	ast.Inspect(m.file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok && m.isPkg(sel.X, name) && !shared[sel.Sel.Name] {
+			only = false
+		}
+		return only
+	})
+	return only
+}
+
+// errorsUsed reports whether the file refers to github.com/pkg/errors.
+func (m *migrator) errorsUsed() bool {
+	used := false
+	ast.Inspect(m.file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok && m.isPkg(sel.X, m.errors) {
+			used = true
+		}
+		return !used
+	})
+	return used
+}
+
+// errorsFunc returns the name of the function of github.com/pkg/errors
+// called by call, or "" if call is not a call of one.
+func (m *migrator) errorsFunc(call *ast.CallExpr) string {
+	if sel, ok := call.Fun.(*ast.SelectorExpr); ok && m.isPkg(sel.X, m.errors) {
+		return sel.Sel.Name
+	}
+	return ""
+}
+
+// isFunc reports whether call is a call of the function name of the
+// package imported from path.
+func (m *migrator) isFunc(call *ast.CallExpr, path, name string) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	return ok && sel.Sel.Name == name && m.isPkg(sel.X, importName(m.file, path))
+}
+
+// isPkg reports whether x refers to the package imported with name.
+func (m *migrator) isPkg(x ast.Expr, name string) bool {
+	id, ok := x.(*ast.Ident)
+	return ok && name != "" && id.Name == name && id.Obj == nil
+}
+
+// fmtFunc returns the function name of fmt, at the position of old if it
+// is not nil.
+func (m *migrator) fmtFunc(old ast.Expr, name string) ast.Expr {
+	m.fmtUsed = true
+	fmtName := importName(m.file, "fmt")
+	if fmtName == "" || fmtName == "_" || fmtName == "." {
+		fmtName = "fmt"
+	}
+	x := ast.NewIdent(fmtName)
+	if old != nil {
+		x.NamePos = old.Pos()
+	}
+	return &ast.SelectorExpr{X: x, Sel: ast.NewIdent(name)}
+}
+
+// guarded reports whether the node being rewritten is reached only when x
+// is not nil: it is in the body of an if statement whose condition tests
+// that x != nil, or follows an if statement that returns if x == nil.
+func (m *migrator) guarded(x ast.Expr) bool {
+	want := m.render(x)
+	for i := len(m.stack) - 2; i >= 0; i-- {
+		child := m.stack[i+1]
+		switch n := m.stack[i].(type) {
+		case *ast.IfStmt:
+			if child == n.Body && m.tests(n.Cond, want, token.NEQ) {
+				return true
+			}
+		case *ast.BlockStmt:
+			for _, s := range n.List {
+				if s == child {
+					break
+				}
+				if is, ok := s.(*ast.IfStmt); ok && is.Init == nil && is.Else == nil &&
+					m.tests(is.Cond, want, token.EQL) && returns(is.Body) {
+					return true
+				}
+			}
+		case *ast.FuncLit, *ast.FuncDecl:
+			return false
+		}
+	}
+	return false
+}
+
+// tests reports whether cond is true only if the expression want compares
+// to nil by op: it is want op nil, or a conjunction including it if op is
+// != or a disjunction if op is ==.
+func (m *migrator) tests(cond ast.Expr, want string, op token.Token) bool {
+	switch c := ast.Unparen(cond).(type) {
+	case *ast.BinaryExpr:
+		if c.Op == token.LAND && op == token.NEQ || c.Op == token.LOR && op == token.EQL {
+			return m.tests(c.X, want, op) || m.tests(c.Y, want, op)
+		}
+		if c.Op != op {
+			return false
+		}
+		return isNil(c.Y) && m.render(c.X) == want || isNil(c.X) && m.render(c.Y) == want
+	}
+	return false
+}
+
+// render returns the source of the expression x.
+func (m *migrator) render(x ast.Expr) string {
+	var b bytes.Buffer
+	format.Node(&b, m.fset, x)
+	return b.String()
+}
+
+// importName returns the name by which f refers to the package imported
+// from path, or "" if f does not import it.
+func importName(f *ast.File, path string) string {
+	for _, imp := range f.Imports {
+		if p, _ := strconv.Unquote(imp.Path.Value); p != path {
+			continue
+		}
+		if imp.Name != nil {
+			return imp.Name.Name
+		}
+		return path[strings.LastIndex(path, "/")+1:]
+	}
+	return ""
+}
+
+// zero returns an expression for the zero value of the type t.
+func zero(t ast.Expr) ast.Expr {
+	if _, ok := t.(*ast.StarExpr); ok {
+		return &ast.CallExpr{Fun: &ast.ParenExpr{X: t}, Args: []ast.Expr{ast.NewIdent("nil")}}
+	}
+	return &ast.StarExpr{X: &ast.CallExpr{Fun: ast.NewIdent("new"), Args: []ast.Expr{t}}}
+}
+
+// isVar reports whether x is a variable or a field of one, which can be
+// evaluated more than once.
+func isVar(x ast.Expr) bool {
+	switch x := x.(type) {
+	case *ast.Ident:
+		return true
+	case *ast.SelectorExpr:
+		return isVar(x.X)
+	}
+	return false
+}
+
+// copyVar returns a copy at pos of x, an expression for which isVar is
+// true.
+func copyVar(x ast.Expr, pos token.Pos) ast.Expr {
+	switch x := x.(type) {
+	case *ast.Ident:
+		return &ast.Ident{NamePos: pos, Name: x.Name}
+	case *ast.SelectorExpr:
+		return &ast.SelectorExpr{X: copyVar(x.X, pos), Sel: &ast.Ident{NamePos: pos, Name: x.Sel.Name}}
+	}
+	return x
+}
+
+func isNil(x ast.Expr) bool {
+	id, ok := x.(*ast.Ident)
+	return ok && id.Name == "nil"
+}
+
+// uses reports whether the statements refer to name.
+func uses(stmts []ast.Stmt, name string) bool {
+	used := false
+	for _, s := range stmts {
+		ast.Inspect(s, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok && id.Name == name {
+				used = true
+			}
+			return !used
+		})
+	}
+	return used
+}
+
+// fresh returns base, or base followed by the first number from 1 that
+// makes it a name to which the statements do not refer.
+func fresh(stmts []ast.Stmt, base string) string {
+	name := base
+	for i := 1; uses(stmts, name); i++ {
+		name = base + strconv.Itoa(i)
+	}
+	return name
+}
+
+// breaks reports whether the statements have a break statement without a
+// label that is not within a statement of their own that it would end.
+func breaks(stmts []ast.Stmt) bool {
+	found := false
+	for _, s := range stmts {
+		ast.Inspect(s, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.ForStmt, *ast.RangeStmt, *ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.SelectStmt, *ast.FuncLit:
+				return false
+			case *ast.BranchStmt:
+				if n.Tok == token.BREAK && n.Label == nil {
+					found = true
+				}
+			}
+			return !found
+		})
+	}
+	return found
+}
+
+// returns reports whether the block ends by returning or panicking.
+func returns(b *ast.BlockStmt) bool {
+	if len(b.List) == 0 {
+		return false
+	}
+	switch s := b.List[len(b.List)-1].(type) {
+	case *ast.ReturnStmt:
+		return true
+	case *ast.ExprStmt:
+		call, ok := s.X.(*ast.CallExpr)
+		if !ok {
+			return false
+		}
+		id, ok := call.Fun.(*ast.Ident)
+		return ok && id.Name == "panic"
+	}
+	return false
+}
+
+// quote returns s as a string literal in the style of lit.
+func quote(lit *ast.BasicLit, s string) string {
+	if strings.HasPrefix(lit.Value, "`") && !strings.Contains(s, "`") {
+		return "`" + s + "`"
+	}
+	return strconv.Quote(s)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors/errtest"
+)
+
+func TestMigrate(t *testing.T) {
+	tests := []struct {
+		name string
+		s    style
+	}{
+		{"std", std},
+		{"stdonly", std},
+		{"pkg", pkg},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, err := ioutil.ReadFile(filepath.Join("testdata", tt.name+".input"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out, reports bytes.Buffer
+			if err := process(tt.name+".go", src, tt.s, &out, &reports); err != nil {
+				t.Fatal(err)
+			}
+			errtest.Golden(t, tt.name, out.String())
+			errtest.Golden(t, tt.name+".reports", reports.String())
+		})
+	}
+}
+
+func TestWalk(t *testing.T) {
+	defer func(w bool) { *write = w }(*write)
+	*write = true
+
+	src := "package a\n\nimport \"github.com/pkg/errors\"\n\nvar err = errors.Errorf(\"x\")\n"
+	root := filepath.Join(t.TempDir(), ".project")
+	files := map[string]bool{
+		"a.go":            true,
+		"b/b.go":          true,
+		".hidden/c.go":    false,
+		"_skip/d.go":      false,
+		"testdata/e.go":   false,
+		"b/vendor/x/f.go": false,
+	}
+	for name := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := walk(root, std); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range files {
+		b, err := ioutil.ReadFile(filepath.Join(root, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := !strings.Contains(string(b), "errors.Errorf"); got != want {
+			t.Errorf("walk(%q): %s rewritten: got %v, want %v", root, name, got, want)
+		}
+	}
+}
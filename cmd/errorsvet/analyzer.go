@@ -0,0 +1,545 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/format"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors/internal/printf"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const doc = `report common misuses of github.com/pkg/errors
+
+The errorsvet analyzer reports calls that record a second stack trace for
+an error that already has one, Errorf with %w, discarded results of Wrap
+and the other wrapping functions, comparisons with == of errors after they
+have been wrapped, and fmt.Errorf calls that drop the chain of the errors
+they format.`
+
+var analyzer = &analysis.Analyzer{
+	Name: "errorsvet",
+	Doc:  doc,
+	Run:  run,
+}
+
+// errorsPath is the import path of the package whose use is checked.
+const errorsPath = "github.com/pkg/errors"
+
+// Functions of errorsPath grouped by what they do to an error.
+var (
+	// records are the functions that return an error with a new stack trace.
+	records = map[string]bool{
+		"New": true, "Errorf": true, "LazyErrorf": true,
+		"Wrap": true, "Wrapf": true, "LazyWrapf": true, "WithStack": true,
+	}
+
+	// wraps are the functions that return their first argument wrapped.
+	wraps = map[string]bool{
+		"Wrap": true, "Wrapf": true, "LazyWrapf": true, "WithStack": true,
+		"WithMessage": true, "WithMessagef": true, "LazyWithMessagef": true,
+	}
+
+	// messages maps the functions that wrap an error with a message and a
+	// stack trace to those that add the message alone.
+	messages = map[string]string{
+		"Wrap": "WithMessage", "Wrapf": "WithMessagef", "LazyWrapf": "LazyWithMessagef",
+	}
+)
+
+var errorType = types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+			c := &checker{
+				pass:    pass,
+				file:    file,
+				origins: make(map[types.Object]origin),
+			}
+			ast.Inspect(fn.Body, c.visit)
+		}
+	}
+	return nil, nil
+}
+
+// origin records how a variable's error was made earlier in a function.
+type origin struct {
+	stack    string    // function of errorsPath that recorded its stack trace, if any
+	stackPos token.Pos // position of the call to stack
+	wrap     string    // function of errorsPath that last wrapped it, if any
+	wrapPos  token.Pos // position of the call to wrap
+	block    ast.Node  // block in which the variable was assigned
+}
+
+// checker checks the body of a function.
+type checker struct {
+	pass    *analysis.Pass
+	file    *ast.File
+	origins map[types.Object]origin // the variables assigned errors made by errorsPath
+	stack   []ast.Node              // the nodes enclosing the one being visited
+}
+
+// visit is the ast.Inspect function that checks each node of the body.
+func (c *checker) visit(n ast.Node) bool {
+	if n == nil {
+		c.stack = c.stack[:len(c.stack)-1]
+		return true
+	}
+	switch n := n.(type) {
+	case *ast.AssignStmt:
+		for _, r := range n.Rhs {
+			ast.Inspect(r, c.visit)
+		}
+		for i, l := range n.Lhs {
+			var r ast.Expr
+			if len(n.Lhs) == len(n.Rhs) {
+				r = n.Rhs[i]
+			}
+			c.assign(l, r)
+		}
+		return false
+	case *ast.ValueSpec:
+		for _, v := range n.Values {
+			ast.Inspect(v, c.visit)
+		}
+		for i, name := range n.Names {
+			var v ast.Expr
+			if len(n.Names) == len(n.Values) {
+				v = n.Values[i]
+			}
+			c.assign(name, v)
+		}
+		return false
+	case *ast.UnaryExpr:
+		if n.Op == token.AND {
+			c.assign(n.X, nil)
+		}
+	case *ast.ExprStmt:
+		if call, ok := ast.Unparen(n.X).(*ast.CallExpr); ok {
+			c.checkDiscarded(n, call)
+		}
+	case *ast.CallExpr:
+		c.checkCall(n)
+	case *ast.BinaryExpr:
+		c.checkComparison(n)
+	}
+	c.stack = append(c.stack, n)
+	return true
+}
+
+// assign records that the variable l, if it is one, was assigned r, which
+// is nil if the value assigned is unknown.
+func (c *checker) assign(l, r ast.Expr) {
+	id, ok := ast.Unparen(l).(*ast.Ident)
+	if !ok {
+		return
+	}
+	obj := c.pass.TypesInfo.ObjectOf(id)
+	if obj == nil {
+		return
+	}
+	if r != nil {
+		if o, ok := c.origin(r); ok {
+			o.block = c.block()
+			c.origins[obj] = o
+			return
+		}
+	}
+	delete(c.origins, obj)
+}
+
+// block returns the innermost block enclosing the node being visited.
+func (c *checker) block() ast.Node {
+	for i := len(c.stack) - 1; i >= 0; i-- {
+		switch n := c.stack[i].(type) {
+		case *ast.BlockStmt, *ast.CaseClause, *ast.CommClause:
+			return n
+		}
+	}
+	return nil
+}
+
+// origin returns how the error e was made, if it is the result of a call
+// to errorsPath or a variable assigned one in a block enclosing the node
+// being visited.
+func (c *checker) origin(e ast.Expr) (origin, bool) {
+	switch e := ast.Unparen(e).(type) {
+	case *ast.Ident:
+		obj := c.pass.TypesInfo.ObjectOf(e)
+		o, ok := c.origins[obj]
+		if !ok || !c.encloses(o.block) {
+			return origin{}, false
+		}
+		return o, true
+	case *ast.CallExpr:
+		name := c.errorsFunc(e)
+		if name == "" {
+			return origin{}, false
+		}
+		var o origin
+		if wraps[name] && len(e.Args) > 0 {
+			o, _ = c.origin(e.Args[0])
+			o.wrap, o.wrapPos = name, e.Pos()
+		}
+		if records[name] {
+			o.stack, o.stackPos = name, e.Pos()
+		}
+		return o, o.stack != "" || o.wrap != ""
+	}
+	return origin{}, false
+}
+
+// encloses reports whether block encloses the node being visited.
+func (c *checker) encloses(block ast.Node) bool {
+	if block == nil {
+		return true
+	}
+	for _, n := range c.stack {
+		if n == block {
+			return true
+		}
+	}
+	return false
+}
+
+// checkCall checks a call of Wrap, Wrapf or WithStack, of Errorf, and of
+// fmt.Errorf.
+func (c *checker) checkCall(call *ast.CallExpr) {
+	if c.isFunc(call, "fmt", "Errorf") {
+		c.checkFmtErrorf(call)
+		return
+	}
+	name := c.errorsFunc(call)
+	switch name {
+	case "Wrap", "Wrapf", "LazyWrapf", "WithStack":
+		c.checkRewrap(call, name)
+	case "Errorf", "LazyErrorf":
+		c.checkErrorf(call, name)
+	}
+}
+
+// checkRewrap checks that the error given to the Wrap, Wrapf or WithStack
+// call does not already have a stack trace recorded in the function.
+func (c *checker) checkRewrap(call *ast.CallExpr, name string) {
+	if len(call.Args) == 0 {
+		return
+	}
+	o, ok := c.origin(call.Args[0])
+	if !ok || o.stack == "" {
+		return
+	}
+	line := c.pass.Fset.Position(o.stackPos).Line
+	if name == "WithStack" {
+		c.pass.Report(analysis.Diagnostic{
+			Pos:     call.Pos(),
+			End:     call.End(),
+			Message: fmt.Sprintf("errors.WithStack of an error that already has a stack trace from errors.%s on line %d", o.stack, line),
+			SuggestedFixes: []analysis.SuggestedFix{{
+				Message:   "Remove the call to WithStack",
+				TextEdits: []analysis.TextEdit{c.replace(call, c.render(call.Args[0]))},
+			}},
+		})
+		return
+	}
+	with := messages[name]
+	c.pass.Report(analysis.Diagnostic{
+		Pos:     call.Pos(),
+		End:     call.End(),
+		Message: fmt.Sprintf("errors.%s of an error that already has a stack trace from errors.%s on line %d; use errors.%s", name, o.stack, line, with),
+		SuggestedFixes: []analysis.SuggestedFix{{
+			Message:   "Use errors." + with,
+			TextEdits: []analysis.TextEdit{c.replace(funcIdent(call), with)},
+		}},
+	})
+}
+
+// checkErrorf checks that the format of a call to Errorf has no %w verb.
+// When the error is formatted by a trailing ": %w", the call is fixed to
+// Wrap or Wrapf it; otherwise fmt.Errorf is suggested.
+func (c *checker) checkErrorf(call *ast.CallExpr, name string) {
+	format, ok := c.format(call)
+	if !ok {
+		return
+	}
+	vs, ok := printf.Parse(format)
+	if !ok {
+		return
+	}
+	var ws []printf.Verb
+	for _, v := range vs {
+		if v.Verb == 'w' {
+			ws = append(ws, v)
+		}
+	}
+	if len(ws) == 0 {
+		return
+	}
+
+	var fix analysis.SuggestedFix
+	lit, isLit := call.Args[0].(*ast.BasicLit)
+	last := vs[len(vs)-1]
+	if w := ws[0]; name == "Errorf" && isLit && len(ws) == 1 && w == last &&
+		w.End == len(format) && strings.HasSuffix(format[:w.Start], ": ") &&
+		w.Arg == len(call.Args)-2 {
+		prefix := strings.TrimSuffix(format[:w.Start], ": ")
+		args := []string{c.render(call.Args[w.Arg+1]), quote(lit, prefix)}
+		for _, a := range call.Args[1 : len(call.Args)-1] {
+			args = append(args, c.render(a))
+		}
+		with := "Wrap"
+		if len(args) > 2 || strings.Contains(prefix, "%") {
+			with = "Wrapf"
+		}
+		fix = analysis.SuggestedFix{
+			Message:   "Use errors." + with,
+			TextEdits: []analysis.TextEdit{c.replace(call, c.qualify(call, with)+"("+strings.Join(args, ", ")+")")},
+		}
+	} else {
+		fmtName, edits := c.importName("fmt")
+		fix = analysis.SuggestedFix{
+			Message:   "Use fmt.Errorf",
+			TextEdits: append(edits, c.replace(call.Fun, fmtName+".Errorf")),
+		}
+	}
+	c.pass.Report(analysis.Diagnostic{
+		Pos:            call.Pos(),
+		End:            call.End(),
+		Message:        fmt.Sprintf("errors.%s does not support %%w; the error is printed as %%!w and not wrapped", name),
+		SuggestedFixes: []analysis.SuggestedFix{fix},
+	})
+}
+
+// checkFmtErrorf checks that a call to fmt.Errorf formats its errors with
+// %w, so that they remain in the chain of the error it returns.
+func (c *checker) checkFmtErrorf(call *ast.CallExpr) {
+	format, ok := c.format(call)
+	if !ok {
+		return
+	}
+	vs, ok := printf.Parse(format)
+	if !ok {
+		return
+	}
+	var dropped []printf.Verb
+	for _, v := range vs {
+		if v.Verb != 'v' && v.Verb != 's' || v.Arg+1 >= len(call.Args) {
+			continue
+		}
+		t := c.pass.TypesInfo.TypeOf(call.Args[v.Arg+1])
+		if t != nil && types.Implements(t, errorType) {
+			dropped = append(dropped, v)
+		}
+	}
+	if len(dropped) == 0 {
+		return
+	}
+
+	d := analysis.Diagnostic{
+		Pos:     call.Pos(),
+		End:     call.End(),
+		Message: fmt.Sprintf("fmt.Errorf formats %s with %s, dropping its chain; use %%w", c.render(call.Args[dropped[0].Arg+1]), format[dropped[0].Start:dropped[0].End]),
+	}
+	if lit, ok := call.Args[0].(*ast.BasicLit); ok {
+		var b strings.Builder
+		end := 0
+		for _, v := range dropped {
+			b.WriteString(format[end:v.Start])
+			b.WriteString("%w")
+			end = v.End
+		}
+		b.WriteString(format[end:])
+		d.SuggestedFixes = []analysis.SuggestedFix{{
+			Message:   "Format the error with %w",
+			TextEdits: []analysis.TextEdit{c.replace(lit, quote(lit, b.String()))},
+		}}
+	}
+	c.pass.Report(d)
+}
+
+// checkDiscarded checks that the result of a wrapping call used as a
+// statement is not discarded.
+func (c *checker) checkDiscarded(stmt *ast.ExprStmt, call *ast.CallExpr) {
+	name := c.errorsFunc(call)
+	if !wraps[name] {
+		return
+	}
+	d := analysis.Diagnostic{
+		Pos:     call.Pos(),
+		End:     call.End(),
+		Message: fmt.Sprintf("result of errors.%s is not used", name),
+	}
+	if len(call.Args) > 0 {
+		if id, ok := ast.Unparen(call.Args[0]).(*ast.Ident); ok && id.Name != "_" {
+			d.SuggestedFixes = []analysis.SuggestedFix{{
+				Message:   "Assign the result to " + id.Name,
+				TextEdits: []analysis.TextEdit{{Pos: stmt.Pos(), End: stmt.Pos(), NewText: []byte(id.Name + " = ")}},
+			}}
+		}
+	}
+	c.pass.Report(d)
+}
+
+// checkComparison checks that an error wrapped earlier in the function is
+// not compared with another error by == or !=.
+func (c *checker) checkComparison(b *ast.BinaryExpr) {
+	if b.Op != token.EQL && b.Op != token.NEQ {
+		return
+	}
+	for _, pair := range [][2]ast.Expr{{b.X, b.Y}, {b.Y, b.X}} {
+		x, y := pair[0], pair[1]
+		o, ok := c.origin(x)
+		if !ok || o.wrap == "" {
+			continue
+		}
+		if tv, ok := c.pass.TypesInfo.Types[y]; !ok || tv.IsNil() || !types.Implements(tv.Type, errorType) {
+			continue
+		}
+		is := c.qualify(nil, "Is") + "(" + c.render(x) + ", " + c.render(y) + ")"
+		if b.Op == token.NEQ {
+			is = "!" + is
+		}
+		c.pass.Report(analysis.Diagnostic{
+			Pos:     b.Pos(),
+			End:     b.End(),
+			Message: fmt.Sprintf("%s wrapped by errors.%s on line %d can never be equal to %s; use errors.Is", c.render(x), o.wrap, c.pass.Fset.Position(o.wrapPos).Line, c.render(y)),
+			SuggestedFixes: []analysis.SuggestedFix{{
+				Message:   "Use errors.Is",
+				TextEdits: []analysis.TextEdit{c.replace(b, is)},
+			}},
+		})
+		return
+	}
+}
+
+// errorsFunc returns the name of the function of errorsPath called by
+// call, or "" if call is not a call of one.
+func (c *checker) errorsFunc(call *ast.CallExpr) string {
+	fn, ok := typeutil.Callee(c.pass.TypesInfo, call).(*types.Func)
+	if !ok || fn.Pkg() == nil || !isErrorsPath(fn.Pkg().Path()) {
+		return ""
+	}
+	if sig, ok := fn.Type().(*types.Signature); !ok || sig.Recv() != nil {
+		return ""
+	}
+	return fn.Name()
+}
+
+// isErrorsPath reports whether path is that of errorsPath, possibly
+// vendored.
+func isErrorsPath(path string) bool {
+	return path == errorsPath || strings.HasSuffix(path, "/vendor/"+errorsPath)
+}
+
+// isFunc reports whether call is a call of the function pkg.name.
+func (c *checker) isFunc(call *ast.CallExpr, pkg, name string) bool {
+	fn, ok := typeutil.Callee(c.pass.TypesInfo, call).(*types.Func)
+	return ok && fn.Pkg() != nil && fn.Pkg().Path() == pkg && fn.Name() == name
+}
+
+// format returns the constant format string that is the first argument of
+// call.
+func (c *checker) format(call *ast.CallExpr) (string, bool) {
+	if len(call.Args) == 0 {
+		return "", false
+	}
+	tv, ok := c.pass.TypesInfo.Types[call.Args[0]]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return "", false
+	}
+	return constant.StringVal(tv.Value), true
+}
+
+// qualify returns name qualified as a function of errorsPath in the
+// checked file, as it is in call if call is not nil.
+func (c *checker) qualify(call *ast.CallExpr, name string) string {
+	if call != nil {
+		if sel, ok := call.Fun.(*ast.SelectorExpr); ok {
+			if x, ok := sel.X.(*ast.Ident); ok {
+				return x.Name + "." + name
+			}
+		}
+		return name
+	}
+	for _, imp := range c.file.Imports {
+		path, _ := strconv.Unquote(imp.Path.Value)
+		if !isErrorsPath(path) {
+			continue
+		}
+		if imp.Name == nil {
+			return "errors." + name
+		}
+		if imp.Name.Name == "." {
+			return name
+		}
+		return imp.Name.Name + "." + name
+	}
+	return "errors." + name
+}
+
+// importName returns the name by which the checked file refers to the
+// package path, with the edits that import it if the file does not.
+func (c *checker) importName(path string) (string, []analysis.TextEdit) {
+	for _, imp := range c.file.Imports {
+		if p, _ := strconv.Unquote(imp.Path.Value); p != path {
+			continue
+		}
+		if imp.Name == nil {
+			return path, nil
+		}
+		if imp.Name.Name != "_" && imp.Name.Name != "." {
+			return imp.Name.Name, nil
+		}
+	}
+	pos, text := c.file.Name.End(), "\n\nimport "+strconv.Quote(path)
+	for _, decl := range c.file.Decls {
+		if d, ok := decl.(*ast.GenDecl); ok && d.Tok == token.IMPORT {
+			if d.Lparen.IsValid() {
+				pos, text = d.Lparen+1, "\n\t"+strconv.Quote(path)
+			} else {
+				pos, text = d.End(), "\nimport "+strconv.Quote(path)
+			}
+			break
+		}
+	}
+	return path, []analysis.TextEdit{{Pos: pos, End: pos, NewText: []byte(text)}}
+}
+
+// replace returns the edit that replaces n with text.
+func (c *checker) replace(n ast.Node, text string) analysis.TextEdit {
+	return analysis.TextEdit{Pos: n.Pos(), End: n.End(), NewText: []byte(text)}
+}
+
+// render returns the source of the expression e.
+func (c *checker) render(e ast.Expr) string {
+	var b bytes.Buffer
+	format.Node(&b, c.pass.Fset, e)
+	return b.String()
+}
+
+// funcIdent returns the identifier that names the function called by call.
+func funcIdent(call *ast.CallExpr) ast.Node {
+	if sel, ok := call.Fun.(*ast.SelectorExpr); ok {
+		return sel.Sel
+	}
+	return call.Fun
+}
+
+// quote returns s as a string literal in the style of lit.
+func quote(lit *ast.BasicLit, s string) string {
+	if strings.HasPrefix(lit.Value, "`") && !strings.Contains(s, "`") {
+		return "`" + s + "`"
+	}
+	return strconv.Quote(s)
+}
@@ -0,0 +1,25 @@
+// Command errorsvet reports common misuses of github.com/pkg/errors:
+//
+//   - Wrap, Wrapf or WithStack of an error that already has a stack trace
+//     recorded earlier in the same function, by New, Errorf or another
+//     Wrap, which records a second, redundant stack trace;
+//   - Errorf with the %w verb, which only fmt.Errorf supports;
+//   - a call to Wrap or another wrapping function whose result is
+//     discarded, losing the error it returns;
+//   - a comparison, with == or !=, of an error wrapped earlier in the same
+//     function with another error, which can no longer be equal;
+//   - fmt.Errorf formatting an error with a verb other than %w, which
+//     drops the chain of the errors it formats.
+//
+// Each report comes with a suggested fix. errorsvet may be run alone,
+//
+//	errorsvet ./...
+//
+// or by go vet, which runs it in place of its own checks:
+//
+//	go vet -vettool=$(which errorsvet) ./...
+package main
+
+import "golang.org/x/tools/go/analysis/singlechecker"
+
+func main() { singlechecker.Main(analyzer) }
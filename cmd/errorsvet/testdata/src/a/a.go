@@ -0,0 +1,86 @@
+package a
+
+import (
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+var ErrNotFound = errors.New("not found")
+
+func open(name string) error {
+	_, err := os.Open(name)
+	return err
+}
+
+func rewrap(name string) error {
+	err := open(name)
+	if err != nil {
+		err = errors.Wrap(err, "open")
+		return errors.Wrapf(err, "load %s", name) // want `errors.Wrapf of an error that already has a stack trace from errors.Wrap on line 20; use errors.WithMessagef`
+	}
+	return nil
+}
+
+func rewrapNested() error {
+	return errors.Wrap(errors.New("whoops"), "load") // want `errors.Wrap of an error that already has a stack trace from errors.New on line 27; use errors.WithMessage`
+}
+
+func rewrapBranch(name string, retry bool) error {
+	err := open(name)
+	if retry {
+		err = errors.Wrap(err, "retry")
+	}
+	return errors.Wrap(err, "open")
+}
+
+func rewrapReassigned(name string) error {
+	err := errors.Wrap(open(name), "open")
+	err = open(name)
+	return errors.Wrap(err, "again")
+}
+
+func withStackNew() error {
+	err := errors.New("whoops")
+	return errors.WithStack(err) // want `errors.WithStack of an error that already has a stack trace from errors.New on line 45`
+}
+
+func withStackSentinel() error {
+	return errors.WithStack(ErrNotFound)
+}
+
+func errorfWrap(name string) error {
+	err := open(name)
+	if name == "" {
+		return errors.Errorf("open: %w", err) // want `errors.Errorf does not support %w; the error is printed as %!w and not wrapped`
+	}
+	if name == "-" {
+		return errors.Errorf("open %s: %w", name, err) // want `errors.Errorf does not support %w`
+	}
+	return errors.Errorf("open %w: %s", err, name) // want `errors.Errorf does not support %w`
+}
+
+func discarded(name string) error {
+	err := open(name)
+	errors.Wrap(err, "open")               // want `result of errors.Wrap is not used`
+	errors.WithMessage(open(name), "open") // want `result of errors.WithMessage is not used`
+	return err
+}
+
+func compare(name string) bool {
+	err := errors.WithMessage(open(name), "open")
+	if err == io.EOF { // want `err wrapped by errors.WithMessage on line 72 can never be equal to io.EOF; use errors.Is`
+		return true
+	}
+	return ErrNotFound != err // want `err wrapped by errors.WithMessage on line 72 can never be equal to ErrNotFound`
+}
+
+func compareNil(name string) bool {
+	err := errors.Wrap(open(name), "open")
+	return err != nil
+}
+
+func compareUnwrapped(name string) bool {
+	return open(name) == io.EOF
+}
@@ -0,0 +1,17 @@
+package a
+
+import "fmt"
+
+func dropChain(name string) error {
+	err := open(name)
+	if name == "" {
+		return fmt.Errorf("open %s: %v", name, err) // want `fmt.Errorf formats err with %v, dropping its chain; use %w`
+	}
+	if name == "-" {
+		return fmt.Errorf("open: %+v", err) // want `fmt.Errorf formats err with %\+v`
+	}
+	if name == "--" {
+		return fmt.Errorf("open %v: %w", name, err)
+	}
+	return fmt.Errorf("open: %w", err)
+}
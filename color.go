@@ -0,0 +1,66 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// Colors holds the ANSI escape sequences used to highlight each part of
+// an error printed with Colorize. An empty sequence leaves that part
+// unhighlighted, so the zero value prints exactly as the error's own
+// Format method does.
+type Colors struct {
+	Message  string // error messages
+	Function string // function names
+	File     string // source file paths
+	Line     string // source line numbers
+}
+
+// DefaultColors is the highlighting returned by ColorsFor for terminals.
+var DefaultColors = Colors{
+	Message:  "\x1b[1;31m",
+	Function: "\x1b[36m",
+	File:     "\x1b[2m",
+	Line:     "\x1b[33m",
+}
+
+// colorReset restores the terminal's default attributes.
+const colorReset = "\x1b[0m"
+
+// ColorsFor returns DefaultColors if f is a terminal, otherwise the zero
+// Colors. Highlighting is also disabled when the NO_COLOR environment
+// variable is set to a non-empty value.
+func ColorsFor(f *os.File) Colors {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return Colors{}
+	}
+	fi, err := f.Stat()
+	if err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return Colors{}
+	}
+	return DefaultColors
+}
+
+// Colorize returns a fmt.Formatter that prints err highlighted with c.
+// It supports the same verbs as the errors returned by this package:
+//
+//	fmt.Fprintf(os.Stderr, "%+v\n", errors.Colorize(err, errors.ColorsFor(os.Stderr)))
+//
+// Errors not created by this package are printed with %+v, highlighted
+// as a message.
+func Colorize(err error, c Colors) fmt.Formatter {
+	return Format(err, TextFormatter{Colors: c})
+}
+
+// paint writes str to w surrounded by the escape sequence color and a
+// reset. If color is empty, str is written unchanged.
+func paint(w io.Writer, color, str string) {
+	if color == "" {
+		io.WriteString(w, str)
+		return
+	}
+	io.WriteString(w, color)
+	io.WriteString(w, str)
+	io.WriteString(w, colorReset)
+}
@@ -0,0 +1,82 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+var testColors = Colors{
+	Message:  "<m>",
+	Function: "<n>",
+	File:     "<f>",
+	Line:     "<l>",
+}
+
+func TestColorize(t *testing.T) {
+	tests := []struct {
+		err    error
+		format string
+		want   string
+	}{{
+		New("error"),
+		"%s",
+		"<m>error\x1b\\[0m",
+	}, {
+		Wrap(io.EOF, "error"),
+		"%v",
+		"<m>error: EOF\x1b\\[0m",
+	}, {
+		New("error"),
+		"%q",
+		"<m>\"error\"\x1b\\[0m",
+	}, {
+		New("error"),
+		"%+v",
+		"<m>error\x1b\\[0m\n" +
+			"<n>github.com/pkg/errors.TestColorize\x1b\\[0m\n" +
+			"\t<f>.+/github.com/pkg/errors/color_test.go\x1b\\[0m:<l>36\x1b\\[0m",
+	}, {
+		WithMessage(io.EOF, "error"),
+		"%+v",
+		"<m>EOF\x1b\\[0m\n" +
+			"<m>error\x1b\\[0m",
+	}}
+
+	for i, tt := range tests {
+		testFormatRegexp(t, i, Colorize(tt.err, testColors), tt.format, tt.want)
+	}
+}
+
+func TestColorizeZero(t *testing.T) {
+	errs := []error{
+		New("error"),
+		Wrap(New("error"), "wrapped"),
+		WithMessage(WithStack(io.EOF), "message"),
+		io.EOF,
+	}
+	for _, err := range errs {
+		for _, format := range []string{"%s", "%v", "%+v"} {
+			got := fmt.Sprintf(format, Colorize(err, Colors{}))
+			want := fmt.Sprintf(format, err)
+			if got != want {
+				t.Errorf("fmt.Sprintf(%q, Colorize(%v, Colors{})):\n got: %q\nwant: %q", format, err, got, want)
+			}
+		}
+	}
+}
+
+func TestColorsFor(t *testing.T) {
+	f, err := ioutil.TempFile("", "colors")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	if got := ColorsFor(f); got != (Colors{}) {
+		t.Errorf("ColorsFor(%s): got %q, want zero Colors", f.Name(), got)
+	}
+}
@@ -62,6 +62,21 @@
 //     %v    see %s
 //     %+v   extended format. Each Frame of the error's StackTrace will
 //           be printed in detail.
+//     %+#v  as %+v, with the lines of source code surrounding each Frame
+//           when the source files are available.
+//
+// A precision limits each stack trace in the extended format to that many
+// frames, so %+.5v prints the five innermost frames of each. A width, as in
+// %+4v, indents the source file of each frame with that many spaces in
+// place of a tab.
+//
+// The layout of the extended format is decided by a Formatter. The default,
+// TextFormatter, prints each message after the messages it wraps, followed
+// by the stack recorded with it; TextFormatter{Order: OutermostFirst}
+// reverses that order to match Error. SetFormatter replaces the Formatter
+// for the whole program, and Format applies one to a single error:
+//
+//     fmt.Printf("%+v", errors.Format(err, errors.TreeFormatter{}))
 //
 // Retrieving the stack trace of an error or wrapper
 //
@@ -94,7 +109,7 @@ package errors
 
 import (
 	"fmt"
-	"io"
+	"time"
 )
 
 // New returns an error with the supplied message.
@@ -103,6 +118,7 @@ func New(message string) error {
 	return &fundamental{
 		msg:   message,
 		stack: callers(),
+		at:    now(),
 	}
 }
 
@@ -110,34 +126,30 @@ func New(message string) error {
 // as a value that satisfies error.
 // Errorf also records the stack trace at the point it was called.
 func Errorf(format string, args ...interface{}) error {
+	msg, d := sprintf(format, args)
 	return &fundamental{
-		msg:   fmt.Sprintf(format, args...),
-		stack: callers(),
+		msg:      msg,
+		deferred: d,
+		stack:    callers(),
+		at:       now(),
 	}
 }
 
 // fundamental is an error that has a message and a stack, but no caller.
 type fundamental struct {
-	msg string
+	msg      string
+	deferred *deferred // set if msg is formatted lazily or is sensitive
 	*stack
+	at *time.Time // when f was created, if timestamps are enabled
 }
 
-func (f *fundamental) Error() string { return f.msg }
+func (f *fundamental) Error() string { return f.message() }
+
+// message returns f's own message.
+func (f *fundamental) message() string { return f.deferred.message(f.msg) }
 
 func (f *fundamental) Format(s fmt.State, verb rune) {
-	switch verb {
-	case 'v':
-		if s.Flag('+') {
-			io.WriteString(s, f.msg)
-			f.stack.Format(s, verb)
-			return
-		}
-		fallthrough
-	case 's':
-		io.WriteString(s, f.msg)
-	case 'q':
-		fmt.Fprintf(s, "%q", f.msg)
-	}
+	currentFormatter().FormatError(s, verb, f)
 }
 
 // WithStack annotates err with a stack trace at the point WithStack was called.
@@ -149,12 +161,33 @@ func WithStack(err error) error {
 	return &withStack{
 		err,
 		callers(),
+		now(),
+	}
+}
+
+// WithStackTrace annotates err with st in place of a stack trace recorded
+// where it is called, as for an error decoded from another process along
+// with its trace. The Frames of st may be made by NewFrame.
+// If err is nil, WithStackTrace returns nil.
+func WithStackTrace(err error, st StackTrace) error {
+	if err == nil {
+		return nil
+	}
+	s := make(stack, len(st))
+	for i, f := range st {
+		s[i] = uintptr(f)
+	}
+	return &withStack{
+		err,
+		&s,
+		nil,
 	}
 }
 
 type withStack struct {
 	error
 	*stack
+	at *time.Time // when the stack was recorded, if timestamps are enabled
 }
 
 func (w *withStack) Cause() error { return w.error }
@@ -163,19 +196,7 @@ func (w *withStack) Cause() error { return w.error }
 func (w *withStack) Unwrap() error { return w.error }
 
 func (w *withStack) Format(s fmt.State, verb rune) {
-	switch verb {
-	case 'v':
-		if s.Flag('+') {
-			fmt.Fprintf(s, "%+v", w.Cause())
-			w.stack.Format(s, verb)
-			return
-		}
-		fallthrough
-	case 's':
-		io.WriteString(s, w.Error())
-	case 'q':
-		fmt.Fprintf(s, "%q", w.Error())
-	}
+	currentFormatter().FormatError(s, verb, w)
 }
 
 // Wrap returns an error annotating err with a stack trace
@@ -192,6 +213,7 @@ func Wrap(err error, message string) error {
 	return &withStack{
 		err,
 		callers(),
+		now(),
 	}
 }
 
@@ -202,13 +224,16 @@ func Wrapf(err error, format string, args ...interface{}) error {
 	if err == nil {
 		return nil
 	}
+	msg, d := sprintf(format, args)
 	err = &withMessage{
-		cause: err,
-		msg:   fmt.Sprintf(format, args...),
+		cause:    err,
+		msg:      msg,
+		deferred: d,
 	}
 	return &withStack{
 		err,
 		callers(),
+		now(),
 	}
 }
 
@@ -230,35 +255,31 @@ func WithMessagef(err error, format string, args ...interface{}) error {
 	if err == nil {
 		return nil
 	}
+	msg, d := sprintf(format, args)
 	return &withMessage{
-		cause: err,
-		msg:   fmt.Sprintf(format, args...),
+		cause:    err,
+		msg:      msg,
+		deferred: d,
 	}
 }
 
 type withMessage struct {
-	cause error
-	msg   string
+	cause    error
+	msg      string
+	deferred *deferred // set if msg is formatted lazily or is sensitive
 }
 
-func (w *withMessage) Error() string { return w.msg + ": " + w.cause.Error() }
+func (w *withMessage) Error() string { return w.message() + ": " + w.cause.Error() }
 func (w *withMessage) Cause() error  { return w.cause }
 
+// message returns w's own message, without that of its cause.
+func (w *withMessage) message() string { return w.deferred.message(w.msg) }
+
 // Unwrap provides compatibility for Go 1.13 error chains.
 func (w *withMessage) Unwrap() error { return w.cause }
 
 func (w *withMessage) Format(s fmt.State, verb rune) {
-	switch verb {
-	case 'v':
-		if s.Flag('+') {
-			fmt.Fprintf(s, "%+v\n", w.Cause())
-			io.WriteString(s, w.msg)
-			return
-		}
-		fallthrough
-	case 's', 'q':
-		io.WriteString(s, w.Error())
-	}
+	currentFormatter().FormatError(s, verb, w)
 }
 
 // Cause returns the underlying cause of the error, if possible.
@@ -286,3 +307,23 @@ func Cause(err error) error {
 	}
 	return err
 }
+
+// Find returns the first error in err's tree for which match returns true,
+// or nil if there is none. The tree is err itself followed, depth first,
+// by the errors found by repeatedly calling an Unwrap() []error,
+// Unwrap() error or Cause() error method, in that order of preference.
+// Find returns nil if err is nil.
+func Find(err error, match func(error) bool) error {
+	if err == nil {
+		return nil
+	}
+	if match(err) {
+		return err
+	}
+	for _, c := range causes(err) {
+		if found := Find(c, match); found != nil {
+			return found
+		}
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+// Package errtest provides assertions about errors for use in tests.
+//
+// Each assertion reports a failure with t.Errorf, describing the
+// difference between the error and what was expected, and returns whether
+// it succeeded so that a test can stop early if it needs to:
+//
+//	err := Load("missing.json")
+//	errtest.Messages(t, err, "load missing.json", "open missing.json", "no such file or directory")
+//	errtest.Is(t, err, fs.ErrNotExist)
+//	errtest.StackAt(t, err, "Load")
+//
+// GoldenFormat compares the formatted error, with its stack traces
+// normalized by errors.Normalize, with a file kept under testdata that is
+// rewritten when the tests are run with -errtest.update.
+package errtest
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// T is the subset of testing.TB used by the assertions in this package.
+type T interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// Messages asserts that the messages added by the layers of err, as
+// returned by errors.Messages, are want, outermost first.
+func Messages(t T, err error, want ...string) bool {
+	t.Helper()
+	got := errors.Messages(err)
+	if equal(got, want) {
+		return true
+	}
+	t.Errorf("messages of %v:\n%s", err, diff(got, want))
+	return false
+}
+
+// Is asserts that errors.Is(err, target) is true.
+func Is(t T, err, target error) bool {
+	t.Helper()
+	if errors.Is(err, target) {
+		return true
+	}
+	t.Errorf("errors.Is(%v, %v) = false, want true\nchain of err:\n%s", err, target, describe(err))
+	return false
+}
+
+// NotIs asserts that errors.Is(err, target) is false.
+func NotIs(t T, err, target error) bool {
+	t.Helper()
+	if !errors.Is(err, target) {
+		return true
+	}
+	t.Errorf("errors.Is(%v, %v) = true, want false\nchain of err:\n%s", err, target, describe(err))
+	return false
+}
+
+// As asserts that errors.As(err, target) is true, setting target as As
+// does.
+func As(t T, err error, target interface{}) bool {
+	t.Helper()
+	if errors.As(err, target) {
+		return true
+	}
+	t.Errorf("errors.As(%v, %T) = false, want true\nchain of err:\n%s", err, target, describe(err))
+	return false
+}
+
+// StackAt asserts that a stack trace in err's chain was recorded in the
+// function fn; that is, the innermost frame of the stack is in fn. fn is
+// either a fully qualified function name, such as
+// "github.com/pkg/errors.Wrap", or a suffix of one following a '/' or
+// '.', such as "errors.Wrap", "Wrap" or "(*T).Method".
+func StackAt(t T, err error, fn string) bool {
+	t.Helper()
+	var got []string
+	found := false
+	errors.Layers(err)(func(l errors.Layer) bool {
+		if len(l.Stack) == 0 {
+			return true
+		}
+		name := funcName(l.Stack[0])
+		found = matchFunc(name, fn)
+		got = append(got, name)
+		return !found
+	})
+	if found {
+		return true
+	}
+	if len(got) == 0 {
+		t.Errorf("no stack trace in %v, want one recorded in %s", err, fn)
+		return false
+	}
+	t.Errorf("no stack trace in %v recorded in %s; stacks were recorded in:\n\t%s", err, fn, strings.Join(got, "\n\t"))
+	return false
+}
+
+// Format asserts that err formatted with format, such as "%+v", matches
+// the regular expression pattern in full. On failure the output and the
+// pattern are compared line by line to show where they differ.
+func Format(t T, err error, format, pattern string) bool {
+	t.Helper()
+	re, rerr := regexp.Compile("^(?:" + pattern + ")$")
+	if rerr != nil {
+		t.Errorf("invalid pattern: %v", rerr)
+		return false
+	}
+	got := fmt.Sprintf(format, err)
+	if re.MatchString(got) {
+		return true
+	}
+	t.Errorf("fmt.Sprintf(%q, err) does not match pattern:\n%s", format, diffPattern(strings.Split(got, "\n"), strings.Split(pattern, "\n")))
+	return false
+}
+
+// funcName returns the name of the function containing f.
+func funcName(f errors.Frame) string {
+	name := fmt.Sprintf("%+s", f)
+	if i := strings.Index(name, "\n"); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+// matchFunc reports whether the function name matches fn, as described by
+// StackAt.
+func matchFunc(name, fn string) bool {
+	if name == fn {
+		return true
+	}
+	if !strings.HasSuffix(name, fn) {
+		return false
+	}
+	c := name[len(name)-len(fn)-1]
+	return c == '/' || c == '.'
+}
+
+// describe returns the layers of err, one per line, indented by depth.
+func describe(err error) string {
+	var b strings.Builder
+	errors.Layers(err)(func(l errors.Layer) bool {
+		fmt.Fprintf(&b, "\t%s%s %T", strings.Repeat("  ", l.Depth), l.Kind, l.Err)
+		if l.Message != "" {
+			fmt.Fprintf(&b, " %q", l.Message)
+		}
+		b.WriteString("\n")
+		return true
+	})
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// diff returns got and want side by side, marking with '!' the elements
+// that differ.
+func diff(got, want []string) string {
+	return lines(got, want, func(g, w string) bool { return g == w })
+}
+
+// diffPattern is like diff for lines of output and the lines of a pattern
+// they should match.
+func diffPattern(got, want []string) string {
+	return lines(got, want, func(g, w string) bool {
+		ok, _ := regexp.MatchString("^(?:"+w+")$", g)
+		return ok
+	})
+}
+
+// lines returns got and want side by side, marking with '!' the lines
+// for which same returns false.
+func lines(got, want []string, same func(g, w string) bool) string {
+	var b strings.Builder
+	n := len(got)
+	if len(want) > n {
+		n = len(want)
+	}
+	for i := 0; i < n; i++ {
+		mark := " "
+		g, w := "(none)", "(none)"
+		if i < len(got) {
+			g = fmt.Sprintf("%q", got[i])
+		}
+		if i < len(want) {
+			w = fmt.Sprintf("%q", want[i])
+		}
+		if i >= len(got) || i >= len(want) || !same(got[i], want[i]) {
+			mark = "!"
+		}
+		fmt.Fprintf(&b, "%s %d:\n\t got: %s\n\twant: %s\n", mark, i+1, g, w)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
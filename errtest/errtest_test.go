@@ -0,0 +1,146 @@
+package errtest
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+// ownUpdate is an -update flag of the kind a package using errtest may
+// define for golden files of its own, which must not clash with errtest's.
+var ownUpdate = flag.Bool("update", false, "rewrite golden files")
+
+// recorder is a T that records failures.
+type recorder struct {
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func load() error {
+	return errors.Wrap(errors.WithMessage(io.EOF, "read header"), "load config")
+}
+
+func TestAssertions(t *testing.T) {
+	err := load()
+	tests := []struct {
+		name    string
+		assert  func(t T) bool
+		ok      bool
+		failure string
+	}{
+		{"messages", func(t T) bool { return Messages(t, err, "load config", "read header", "EOF") }, true, ""},
+		{"messages differ", func(t T) bool { return Messages(t, err, "load config", "EOF") }, false, "! 2:\n\t got: \"read header\"\n\twant: \"EOF\""},
+		{"messages missing", func(t T) bool { return Messages(t, err, "load config") }, false, "! 2:\n\t got: \"read header\"\n\twant: (none)"},
+		{"is", func(t T) bool { return Is(t, err, io.EOF) }, true, ""},
+		{"is fails", func(t T) bool { return Is(t, err, io.ErrClosedPipe) }, false, "foreign *errors.errorString \"EOF\""},
+		{"not is", func(t T) bool { return NotIs(t, err, io.ErrClosedPipe) }, true, ""},
+		{"not is fails", func(t T) bool { return NotIs(t, err, io.EOF) }, false, "= true, want false"},
+		{"as", func(t T) bool { var pe *os.PathError; return As(t, errors.WithStack(&os.PathError{Err: io.EOF}), &pe) }, true, ""},
+		{"as fails", func(t T) bool { var pe *os.PathError; return As(t, err, &pe) }, false, "errors.As(load config: read header: EOF, **fs.PathError) = false"},
+		{"stack at", func(t T) bool { return StackAt(t, err, "load") }, true, ""},
+		{"stack at qualified", func(t T) bool { return StackAt(t, err, "github.com/pkg/errors/errtest.load") }, true, ""},
+		{"stack at package", func(t T) bool { return StackAt(t, err, "errtest.load") }, true, ""},
+		{"stack at suffix", func(t T) bool { return StackAt(t, err, "oad") }, false, "recorded in:\n\tgithub.com/pkg/errors/errtest.load"},
+		{"stack at none", func(t T) bool { return StackAt(t, io.EOF, "load") }, false, "no stack trace in EOF"},
+		{"format", func(t T) bool { return Format(t, err, "%v", "load config: .*") }, true, ""},
+		{"format lines", func(t T) bool {
+			return Format(t, errors.WithMessage(io.EOF, "outer"), "%+v", "EOF\nouter")
+		}, true, ""},
+		{"format mismatch", func(t T) bool { return Format(t, err, "%v", "load") }, false, "! 1:\n\t got: \"load config: read header: EOF\"\n\twant: \"load\""},
+		{"format invalid", func(t T) bool { return Format(t, err, "%v", "(") }, false, "invalid pattern: "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r recorder
+			if got := tt.assert(&r); got != tt.ok {
+				t.Errorf("got %v, want %v", got, tt.ok)
+			}
+			if tt.ok {
+				if len(r.failures) != 0 {
+					t.Errorf("unexpected failures: %q", r.failures)
+				}
+				return
+			}
+			if len(r.failures) != 1 || !strings.Contains(r.failures[0], tt.failure) {
+				t.Errorf("failures: got %q, want one containing %q", r.failures, tt.failure)
+			}
+		})
+	}
+}
+
+func TestAssertionsTesting(t *testing.T) {
+	err := load()
+	Messages(t, err, "load config", "read header", "EOF")
+	Is(t, err, io.EOF)
+	StackAt(t, err, "load")
+	Format(t, err, "%+v", "EOF\nread header\nload config\n(.+\n\t.+\n?)+")
+}
+
+func TestGoldenFormat(t *testing.T) {
+	err := load()
+	tests := []struct {
+		format string
+		name   string
+	}{
+		{"%v", "load"},
+		{"%+v", "load_extended"},
+		{"%+.1v", "load_depth"},
+	}
+
+	for _, tt := range tests {
+		GoldenFormat(t, err, tt.format, tt.name)
+	}
+}
+
+func TestGoldenMismatch(t *testing.T) {
+	if updating() {
+		t.Skip("golden files are being updated")
+	}
+	tests := []struct {
+		name    string
+		got     string
+		failure string
+	}{
+		{"load", "load config: EOF", "! 1:\n\t got: \"load config: EOF\"\n\twant: \"load config: read header: EOF\""},
+		{"missing", "", "run with -errtest.update to create it"},
+	}
+
+	for _, tt := range tests {
+		var r recorder
+		if Golden(&r, tt.name, tt.got) {
+			t.Errorf("Golden(%q, %q): got true, want false", tt.name, tt.got)
+		}
+		if len(r.failures) != 1 || !strings.Contains(r.failures[0], tt.failure) {
+			t.Errorf("Golden(%q, %q): failures: got %q, want one containing %q", tt.name, tt.got, r.failures, tt.failure)
+		}
+	}
+}
+
+func TestUpdating(t *testing.T) {
+	defer func(own bool) { *ownUpdate = own }(*ownUpdate)
+	defer func(u bool) { *update = u }(*update)
+
+	*update, *ownUpdate = false, false
+	if updating() {
+		t.Errorf("updating() without flags: got true, want false")
+	}
+	*ownUpdate = true
+	if !updating() {
+		t.Errorf("updating() with -update: got false, want true")
+	}
+	*update, *ownUpdate = true, false
+	if !updating() {
+		t.Errorf("updating() with -errtest.update: got false, want true")
+	}
+}
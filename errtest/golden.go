@@ -0,0 +1,76 @@
+package errtest
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var update = flag.Bool("errtest.update", false, "rewrite golden files with the output of the tests")
+
+// updating reports whether golden files are to be rewritten: if the test
+// binary was run with -errtest.update, or with an -update flag defined by
+// the package under test.
+func updating() bool {
+	if *update {
+		return true
+	}
+	if f := flag.Lookup("update"); f != nil {
+		if g, ok := f.Value.(flag.Getter); ok {
+			b, _ := g.Get().(bool)
+			return b
+		}
+	}
+	return false
+}
+
+// Golden asserts that got is the content of the golden file
+// testdata/name.golden. When the test binary is run with the
+// -errtest.update flag, as in
+//
+//	go test -run TestFormat -errtest.update
+//
+// the file is written with got instead, and the assertion succeeds. A
+// package that defines its own boolean -update flag may use that instead.
+func Golden(t T, name, got string) bool {
+	t.Helper()
+	file := filepath.Join("testdata", name+".golden")
+	if updating() {
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Errorf("update golden file: %v", err)
+			return false
+		}
+		if err := ioutil.WriteFile(file, []byte(got), 0644); err != nil {
+			t.Errorf("update golden file: %v", err)
+			return false
+		}
+		return true
+	}
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Errorf("read golden file: %v; run with -errtest.update to create it", err)
+		return false
+	}
+	want := string(b)
+	if got == want {
+		return true
+	}
+	t.Errorf("output does not match %s; run with -errtest.update to accept it:\n%s", file, diff(strings.Split(got, "\n"), strings.Split(want, "\n")))
+	return false
+}
+
+// GoldenFormat asserts that err formatted with format, such as "%+v", by
+// errors.Normalize(nil) is the content of the golden file
+// testdata/name.golden, as Golden does. The paths, line numbers and
+// addresses that would change from one run to the next are replaced by
+// placeholders, and frames in the standard library are left out, as
+// described by errors.Normalize.
+func GoldenFormat(t T, err error, format, name string) bool {
+	t.Helper()
+	return Golden(t, name, fmt.Sprintf(format, errors.Format(err, errors.Normalize(nil))))
+}
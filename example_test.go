@@ -2,6 +2,8 @@ package errors_test
 
 import (
 	"fmt"
+	"io"
+	"os"
 
 	"github.com/pkg/errors"
 )
@@ -203,3 +205,41 @@ func ExampleCause_printf() {
 
 	// Output: failed: hello world
 }
+
+func ExampleNormalize() {
+	err := errors.Wrap(errors.New("whoops"), "oh noes")
+
+	fmt.Printf("%+v\n", errors.Format(err, errors.Normalize(errors.TextFormatter{})))
+
+	// Output:
+	// whoops
+	// github.com/pkg/errors_test.ExampleNormalize
+	//	example_test.go:LINE
+	// oh noes
+	// github.com/pkg/errors_test.ExampleNormalize
+	//	example_test.go:LINE
+}
+
+func ExampleE() {
+	put := errors.E(errors.Op("bucket.Put"), errors.NotExist, errors.Path("a/b"), io.EOF)
+	err := errors.E(errors.Op("store.Save"), errors.Path("a/b"), put)
+
+	fmt.Println(err)
+	fmt.Println(errors.Is(err, errors.NotExist))
+
+	// Output:
+	// store.Save: a/b: bucket.Put: item does not exist: EOF
+	// true
+}
+
+func ExampleReport() {
+	err := errors.WithHint(errors.Wrap(io.EOF, "read tool.conf"), "run 'tool init' first")
+	err = errors.WithDocURL(err, "https://example.com/tool/config")
+
+	errors.Report(os.Stdout, err)
+
+	// Output:
+	// read tool.conf: EOF
+	// docs: https://example.com/tool/config
+	// hint: run 'tool init' first
+}
@@ -0,0 +1,99 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// WithExitCode annotates err with the status with which a command-line
+// program failing with it should exit, as returned by ExitCode and used by
+// Fatal.
+// If err is nil, WithExitCode returns nil.
+func WithExitCode(err error, code int) error {
+	if err == nil {
+		return nil
+	}
+	return &withExitCode{
+		cause: err,
+		code:  code,
+	}
+}
+
+type withExitCode struct {
+	cause error
+	code  int
+}
+
+func (w *withExitCode) Error() string    { return w.cause.Error() }
+func (w *withExitCode) Cause() error     { return w.cause }
+func (w *withExitCode) Annotated() error { return w.cause }
+
+// Unwrap provides compatibility for Go 1.13 error chains.
+func (w *withExitCode) Unwrap() error { return w.cause }
+
+func (w *withExitCode) Format(s fmt.State, verb rune) {
+	currentFormatter().FormatError(s, verb, w)
+}
+
+// ExitCode returns the status with which a command-line program failing
+// with err should exit. It is the code given to the outermost WithExitCode
+// in err's tree or, if there is none, that of the outermost error with an
+// ExitCode() int method, such as *exec.ExitError, that returns a code of
+// zero or more. ExitCode returns 0 if err is nil and 1 if no code is found.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	code, found := 1, false
+	exited := -1
+	Layers(err)(func(l Layer) bool {
+		switch e := l.Err.(type) {
+		case *withExitCode:
+			code, found = e.code, true
+			return false
+		case interface{ ExitCode() int }:
+			if c := e.ExitCode(); c >= 0 && exited < 0 {
+				exited = c
+			}
+		}
+		return true
+	})
+	if !found && exited >= 0 {
+		return exited
+	}
+	return code
+}
+
+// VerboseEnv is the environment variable that, when set to a non-empty
+// value, makes Fatal print the extended format of an error.
+var VerboseEnv = "ERRORS_VERBOSE"
+
+var (
+	stderr io.Writer = os.Stderr
+	exit             = os.Exit
+)
+
+// Fatal ends a command-line program that failed with err. It writes to
+// standard error the program's name and err as printed by Report, or,
+// if the environment variable named by VerboseEnv is set, err printed
+// with %+v, then exits with the status returned by ExitCode:
+//
+//	func main() {
+//		errors.Fatal(run())
+//	}
+//
+// If err is nil, Fatal returns without doing anything.
+func Fatal(err error) {
+	if err == nil {
+		return
+	}
+	if os.Getenv(VerboseEnv) != "" {
+		fmt.Fprintf(stderr, "%+v\n", err)
+	} else {
+		io.WriteString(stderr, filepath.Base(os.Args[0])+": ")
+		Report(stderr, err)
+	}
+	exit(ExitCode(err))
+}
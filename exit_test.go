@@ -0,0 +1,96 @@
+package errors
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+func TestWithExitCodeNil(t *testing.T) {
+	if got := WithExitCode(nil, 2); got != nil {
+		t.Errorf("WithExitCode(nil, 2): got %#v, expected nil", got)
+	}
+}
+
+// exitError is an error with an ExitCode method, as *exec.ExitError has.
+type exitError int
+
+func (e exitError) Error() string { return "exit status" }
+func (e exitError) ExitCode() int { return int(e) }
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, 0},
+		{io.EOF, 1},
+		{WithExitCode(io.EOF, 3), 3},
+		{Wrap(WithExitCode(io.EOF, 3), "read"), 3},
+		{WithExitCode(Wrap(WithExitCode(io.EOF, 3), "read"), 4), 4},
+		{WithExitCode(io.EOF, 0), 0},
+		{Wrap(exitError(2), "run"), 2},
+		{Wrap(exitError(-1), "run"), 1},
+		{WithExitCode(Wrap(exitError(2), "run"), 5), 5},
+		{Wrap(WithMessage(exitError(2), "run"), "x"), 2},
+		{multiError{exitError(-1), exitError(6), WithExitCode(io.EOF, 7)}, 7},
+	}
+
+	for i, tt := range tests {
+		if got := ExitCode(tt.err); got != tt.want {
+			t.Errorf("test %d: ExitCode(%v): got %d, want %d", i+1, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestExitCodeExecExitError(t *testing.T) {
+	path, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("no sh:", err)
+	}
+	err = exec.Command(path, "-c", "exit 7").Run()
+	if got := ExitCode(Wrap(err, "run")); got != 7 {
+		t.Errorf("ExitCode(%v): got %d, want 7", err, got)
+	}
+}
+
+func TestFatal(t *testing.T) {
+	defer func(w io.Writer, f func(int), env string) {
+		stderr, exit, VerboseEnv = w, f, env
+	}(stderr, exit, VerboseEnv)
+	var b bytes.Buffer
+	var code int
+	stderr = &b
+	exit = func(c int) { code = c }
+	VerboseEnv = "ERRORS_TEST_VERBOSE"
+	defer func(arg0 string) { os.Args[0] = arg0 }(os.Args[0])
+	os.Args[0] = "/usr/bin/tool"
+	err := WithHint(WithExitCode(Wrap(io.EOF, "read"), 3), "try again")
+
+	Fatal(err)
+	if got, want := b.String(), "tool: read: EOF\nhint: try again\n"; got != want {
+		t.Errorf("Fatal: got %q, want %q", got, want)
+	}
+	if code != 3 {
+		t.Errorf("Fatal: exited with %d, want 3", code)
+	}
+
+	b.Reset()
+	os.Setenv(VerboseEnv, "1")
+	defer os.Unsetenv(VerboseEnv)
+	Fatal(err)
+	want := "^EOF\n" +
+		"read\n" +
+		"github.com/pkg/errors.TestFatal\n" +
+		"\t.+/github.com/pkg/errors/exit_test.go:\\d+\n"
+	testFormatRegexp(t, 0, b.String(), "%s", want)
+
+	b.Reset()
+	code = -1
+	Fatal(nil)
+	if b.Len() != 0 || code != -1 {
+		t.Errorf("Fatal(nil): wrote %q and exited with %d, want nothing", b.String(), code)
+	}
+}
@@ -0,0 +1,183 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// chain returns err followed by each error beneath it that was created by
+// this package or is an Annotation, outermost first. The walk stops at, and
+// includes, the first error that is neither.
+func chain(err error) []error {
+	var errs []error
+	for err != nil {
+		errs = append(errs, err)
+		switch e := err.(type) {
+		case *withStack:
+			err = e.error
+		case *withMessage:
+			err = e.cause
+		case *Error:
+			err = e.Err
+		case Annotation:
+			err = e.Annotated()
+		default:
+			err = nil
+		}
+	}
+	return errs
+}
+
+// formatChain writes the extended format of err to s, starting with the
+// innermost error, highlighted with c. Errors not created by this package
+// are printed with %+v.
+func formatChain(s fmt.State, err error, c Colors) {
+	errs := chain(err)
+	sevs, ts := severities(errs), times(errs)
+	base := earliest(ts...)
+	for i := len(errs) - 1; i >= 0; i-- {
+		at := offset(ts[i], base)
+		switch e := errs[i].(type) {
+		case *fundamental:
+			paint(s, c.Message, labelled(e.message(), sevs[i], at))
+			formatStack(s, *e.stack, c)
+		case *withStack:
+			if at != "" {
+				io.WriteString(s, "\n")
+				paint(s, c.Message, labelled("", 0, at))
+			}
+			formatStack(s, *e.stack, c)
+		case *withMessage:
+			io.WriteString(s, "\n")
+			paint(s, c.Message, labelled(e.message(), sevs[i], at))
+		case *Error:
+			if msg := labelled(e.message(), sevs[i], at); msg != "" {
+				if i < len(errs)-1 {
+					io.WriteString(s, "\n")
+				}
+				paint(s, c.Message, msg)
+			}
+			if e.stack != nil {
+				formatStack(s, *e.stack, c)
+			}
+		case Annotation:
+		default:
+			paint(s, c.Message, labelled(fmt.Sprintf("%+v", e), sevs[i], at))
+		}
+	}
+}
+
+// formatNodes writes the extended format of err to s, starting with the
+// outermost message, highlighted with c. Each message is followed by the
+// stack recorded with it. Errors not created by this package are printed
+// with %+v.
+func formatNodes(s fmt.State, err error, c Colors) {
+	ns := nodes(err)
+	base := nodesBase(ns)
+	for i, n := range ns {
+		label := n.label(base)
+		switch n.err.(type) {
+		case *fundamental, *withStack, *withMessage, *Error:
+		default:
+			label = labelled(fmt.Sprintf("%+v", n.err), n.severity, offset(n.at, base))
+		}
+		if i > 0 && label != "" {
+			io.WriteString(s, "\n")
+		}
+		paint(s, c.Message, label)
+		if n.stack != nil {
+			formatStack(s, *n.stack, c)
+		}
+	}
+}
+
+// formatStack writes the Frames of st to s in the form used by %+v,
+// highlighted with c. The depth and indentation follow StackTrace.Format.
+func formatStack(s fmt.State, st stack, c Colors) {
+	for _, f := range frames(s, st) {
+		file, line := position(s, f)
+		io.WriteString(s, "\n")
+		paint(s, c.Function, f.name())
+		io.WriteString(s, "\n")
+		io.WriteString(s, indent(s))
+		paint(s, c.File, file)
+		io.WriteString(s, ":")
+		paint(s, c.Line, line)
+		if s.Flag('#') && !normalized(s) {
+			f.formatSource(s, indent(s))
+		}
+	}
+}
+
+// node is a message in an error chain together with the stack recorded
+// where it was added.
+type node struct {
+	err      error // the error that added msg
+	msg      string
+	stack    *stack    // nil if no stack was recorded
+	severity Severity  // attached to msg by WithSeverity, or zero
+	at       time.Time // when msg was added, if timestamps were enabled
+}
+
+// label returns n's message with its severity and its time relative to
+// base, as printed by %+v.
+func (n node) label(base time.Time) string {
+	return labelled(n.msg, n.severity, offset(n.at, base))
+}
+
+// nodesBase returns the time relative to which the times of ns are
+// printed: the earliest of them.
+func nodesBase(ns []node) time.Time {
+	ts := make([]time.Time, len(ns))
+	for i, n := range ns {
+		ts[i] = n.at
+	}
+	return earliest(ts...)
+}
+
+// nodes groups the chain of err into messages, outermost first. A stack
+// added by WithStack, or Wrap, belongs to the message beneath it; a stack
+// with no message beneath it forms a node of its own.
+func nodes(err error) []node {
+	var ns []node
+	var pending *withStack
+	flush := func() {
+		if pending != nil {
+			ns = append(ns, node{err: pending, stack: pending.stack, at: timeOf(pending.at)})
+			pending = nil
+		}
+	}
+	errs := chain(err)
+	sevs, ts := severities(errs), times(errs)
+	for i, e := range errs {
+		switch e := e.(type) {
+		case *withStack:
+			flush()
+			pending = e
+		case *withMessage:
+			n := node{err: e, msg: e.message(), severity: sevs[i], at: ts[i]}
+			if pending != nil {
+				n.stack = pending.stack
+				pending = nil
+			}
+			ns = append(ns, n)
+		case *fundamental:
+			flush()
+			ns = append(ns, node{err: e, msg: e.message(), stack: e.stack, severity: sevs[i], at: ts[i]})
+		case *Error:
+			flush()
+			ns = append(ns, node{err: e, msg: e.message(), stack: e.stack, severity: sevs[i], at: ts[i]})
+		case Annotation:
+		default:
+			n := node{err: e, msg: e.Error(), severity: sevs[i], at: ts[i]}
+			if pending != nil {
+				n.stack = pending.stack
+				pending = nil
+			}
+			ns = append(ns, n)
+		}
+	}
+	flush()
+	return ns
+}
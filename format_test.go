@@ -558,3 +558,34 @@ func testGenericRecursive(t *testing.T, beforeErr error, beforeWant []string, li
 		}
 	}
 }
+
+func TestFormatDepth(t *testing.T) {
+	tests := []struct {
+		error
+		format string
+		want   string
+	}{{
+		New("error"),
+		"%+.1v",
+		"^error\n" +
+			"github.com/pkg/errors.TestFormatDepth\n" +
+			"\t.+/github.com/pkg/errors/format_test.go:568$",
+	}, {
+		Wrap(New("error"), "wrapped"),
+		"%+2.1v",
+		"^error\n" +
+			"github.com/pkg/errors.TestFormatDepth\n" +
+			"  .+/github.com/pkg/errors/format_test.go:574\n" +
+			"wrapped\n" +
+			"github.com/pkg/errors.TestFormatDepth\n" +
+			"  .+/github.com/pkg/errors/format_test.go:574$",
+	}, {
+		WithStack(io.EOF),
+		"%+.0v",
+		"^EOF$",
+	}}
+
+	for i, tt := range tests {
+		testFormatRegexp(t, i, tt.error, tt.format, tt.want)
+	}
+}
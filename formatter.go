@@ -0,0 +1,220 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors/internal/printf"
+)
+
+// A Formatter prints errors for the fmt package. The Format methods of the
+// errors returned by this package delegate to the Formatter installed with
+// SetFormatter, which by default is TextFormatter{}.
+type Formatter interface {
+	// FormatError formats err according to the fmt.Formatter interface.
+	// err may be any error, not only those created by this package.
+	FormatError(s fmt.State, verb rune, err error)
+}
+
+// formatter holds the installed Formatter as a formatterValue.
+var formatter atomic.Value
+
+// formatterValue gives every Formatter stored in formatter the same
+// concrete type, as required by atomic.Value.
+type formatterValue struct{ Formatter }
+
+// SetFormatter installs f as the Formatter used by the errors returned by
+// this package. A nil f restores the default, TextFormatter{}.
+// SetFormatter is safe to call concurrently with formatting.
+func SetFormatter(f Formatter) {
+	if f == nil {
+		f = TextFormatter{}
+	}
+	formatter.Store(formatterValue{f})
+}
+
+// currentFormatter returns the Formatter installed with SetFormatter.
+func currentFormatter() Formatter {
+	if v, ok := formatter.Load().(formatterValue); ok {
+		return v.Formatter
+	}
+	return TextFormatter{}
+}
+
+// Format returns a fmt.Formatter that prints err with f, regardless of the
+// Formatter installed with SetFormatter. For example
+//
+//	log.Printf("%+v", errors.Format(err, errors.CompactFormatter{}))
+//
+// If err is nil, it is printed as fmt prints a nil error, such as <nil>
+// for %v, without calling f.
+func Format(err error, f Formatter) fmt.Formatter {
+	return formatted{err, f}
+}
+
+type formatted struct {
+	err error
+	f   Formatter
+}
+
+func (e formatted) Format(s fmt.State, verb rune) {
+	if e.err == nil {
+		fmt.Fprintf(s, printf.Directive(s, verb), nil)
+		return
+	}
+	e.f.FormatError(s, verb, e.err)
+}
+
+// formatMessage handles the verbs other than %+v, which every Formatter in
+// this package prints the same way.
+func formatMessage(s fmt.State, verb rune, err error, c Colors) {
+	switch verb {
+	case 'v', 's':
+		paint(s, c.Message, err.Error())
+	case 'q':
+		if _, ok := err.(*withMessage); ok {
+			// withMessage has always printed %q unquoted.
+			paint(s, c.Message, err.Error())
+			return
+		}
+		paint(s, c.Message, fmt.Sprintf("%q", err.Error()))
+	}
+}
+
+// Order is the order in which TextFormatter prints the messages of a chain.
+type Order int
+
+const (
+	// InnermostFirst prints the original cause first and the outermost
+	// message last, so the output reads bottom-up.
+	InnermostFirst Order = iota
+
+	// OutermostFirst prints the messages in the order in which Error
+	// returns them, each followed by the stack recorded with it.
+	OutermostFirst
+)
+
+// TextFormatter is the default Formatter. Its %+v prints each message in
+// the chain followed by the stack recorded with it, then any hints
+// attached with WithHint or WithDocURL after a blank line.
+type TextFormatter struct {
+	Colors Colors // highlighting; the zero value disables it
+	Order  Order  // the zero value is InnermostFirst
+}
+
+// FormatError implements Formatter.
+func (t TextFormatter) FormatError(s fmt.State, verb rune, err error) {
+	if verb == 'v' && s.Flag('+') {
+		if t.Order == OutermostFirst {
+			formatNodes(s, err, t.Colors)
+		} else {
+			formatChain(s, err, t.Colors)
+		}
+		formatHints(s, err)
+		return
+	}
+	formatMessage(s, verb, err, t.Colors)
+}
+
+// CompactFormatter is a Formatter whose %+v prints the chain on a single
+// line, outermost first, annotating each message with the location at
+// which it was added:
+//
+//	outer (example.go:50): inner (example.go:48): error (example.go:47)
+type CompactFormatter struct{}
+
+// FormatError implements Formatter.
+func (CompactFormatter) FormatError(s fmt.State, verb rune, err error) {
+	if verb != 'v' || !s.Flag('+') {
+		formatMessage(s, verb, err, Colors{})
+		return
+	}
+	var parts []string
+	ns := nodes(err)
+	base := nodesBase(ns)
+	for _, n := range ns {
+		part := n.label(base)
+		var fs []Frame
+		if n.stack != nil {
+			fs = frames(s, *n.stack)
+		}
+		if len(fs) > 0 {
+			file, line := position(s, fs[0])
+			loc := "(" + path.Base(file) + ":" + line + ")"
+			if part == "" {
+				part = loc
+			} else {
+				part += " " + loc
+			}
+		}
+		parts = append(parts, part)
+	}
+	io.WriteString(s, strings.Join(parts, ": "))
+}
+
+// JSONFormatter is a Formatter whose %+v prints one JSON object per line
+// for each message in the chain, outermost first:
+//
+//	{"message":"outer","stack":["main.f /src/main.go:50", ...]}
+//
+// A message annotated by WithSeverity has its Severity as "severity", and,
+// if timestamps are enabled with SetClock, each message has the time at
+// which it was added as "time" and its offset from the earliest as
+// "offset".
+// Frames are encoded with Frame.MarshalText. A precision, as in %+.5v,
+// limits each stack to that many Frames.
+type JSONFormatter struct{}
+
+type jsonNode struct {
+	Message  string     `json:"message"`
+	Severity string     `json:"severity,omitempty"`
+	Time     *time.Time `json:"time,omitempty"`
+	Offset   string     `json:"offset,omitempty"`
+	Stack    []string   `json:"stack,omitempty"`
+}
+
+// frameText returns f as encoded by Frame.MarshalText, with its position
+// as printed to s.
+func frameText(s fmt.State, f Frame) string {
+	name := f.name()
+	if name == "unknown" {
+		return name
+	}
+	file, line := position(s, f)
+	return name + " " + file + ":" + line
+}
+
+// FormatError implements Formatter.
+func (JSONFormatter) FormatError(s fmt.State, verb rune, err error) {
+	if verb != 'v' || !s.Flag('+') {
+		formatMessage(s, verb, err, Colors{})
+		return
+	}
+	ns := nodes(err)
+	base := nodesBase(ns)
+	for i, n := range ns {
+		if i > 0 {
+			io.WriteString(s, "\n")
+		}
+		jn := jsonNode{Message: n.msg}
+		if n.severity != 0 {
+			jn.Severity = n.severity.String()
+		}
+		if !n.at.IsZero() {
+			at := n.at
+			jn.Time, jn.Offset = &at, offset(n.at, base)
+		}
+		if n.stack != nil {
+			for _, f := range frames(s, *n.stack) {
+				jn.Stack = append(jn.Stack, frameText(s, f))
+			}
+		}
+		b, _ := json.Marshal(jn)
+		s.Write(b)
+	}
+}
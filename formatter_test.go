@@ -0,0 +1,141 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestSetFormatter(t *testing.T) {
+	defer SetFormatter(nil)
+
+	err := WithMessage(io.EOF, "message")
+	SetFormatter(CompactFormatter{})
+	if got, want := fmt.Sprintf("%+v", err), "message: EOF"; got != want {
+		t.Errorf("SetFormatter(CompactFormatter{}): got %q, want %q", got, want)
+	}
+	SetFormatter(nil)
+	if got, want := fmt.Sprintf("%+v", err), "EOF\nmessage"; got != want {
+		t.Errorf("SetFormatter(nil): got %q, want %q", got, want)
+	}
+}
+
+func TestFormatterVerbs(t *testing.T) {
+	formatters := []Formatter{
+		TextFormatter{},
+		CompactFormatter{},
+		JSONFormatter{},
+		TreeFormatter{},
+	}
+	tests := []struct {
+		err    error
+		format string
+		want   string
+	}{
+		{New("error"), "%s", "error"},
+		{New("error"), "%v", "error"},
+		{New("error"), "%q", `"error"`},
+		{Wrap(io.EOF, "error"), "%s", "error: EOF"},
+		{Wrap(io.EOF, "error"), "%q", `"error: EOF"`},
+		{WithMessage(io.EOF, "error"), "%q", "error: EOF"},
+	}
+
+	for _, f := range formatters {
+		for _, tt := range tests {
+			got := fmt.Sprintf(tt.format, Format(tt.err, f))
+			if got != tt.want {
+				t.Errorf("%T: fmt.Sprintf(%q, %v): got %q, want %q", f, tt.format, tt.err, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestCompactFormatter(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{{
+		New("error"),
+		`^error \(formatter_test.go:58\)$`,
+	}, {
+		Wrap(New("error"), "outer"),
+		`^outer \(formatter_test.go:61\): error \(formatter_test.go:61\)$`,
+	}, {
+		WithMessage(WithStack(io.EOF), "outer"),
+		`^outer: EOF \(formatter_test.go:64\)$`,
+	}, {
+		WithStack(WithStack(io.EOF)),
+		`^\(formatter_test.go:67\): EOF \(formatter_test.go:67\)$`,
+	}, {
+		io.EOF,
+		`^EOF$`,
+	}}
+
+	for i, tt := range tests {
+		testFormatRegexp(t, i, Format(tt.err, CompactFormatter{}), "%+v", tt.want)
+	}
+}
+
+func TestJSONFormatter(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{{
+		WithMessage(io.EOF, "outer"),
+		`^{"message":"outer"}` + "\n" +
+			`{"message":"EOF"}$`,
+	}, {
+		Wrap(New("error"), "outer"),
+		`^{"message":"outer","stack":\["github.com/pkg/errors.TestJSONFormatter .+/github.com/pkg/errors/formatter_test.go:88",.+\]}` + "\n" +
+			`{"message":"error","stack":\["github.com/pkg/errors.TestJSONFormatter .+/github.com/pkg/errors/formatter_test.go:88",.+\]}$`,
+	}}
+
+	for i, tt := range tests {
+		testFormatRegexp(t, i, Format(tt.err, JSONFormatter{}), "%+v", tt.want)
+	}
+}
+
+func TestTextFormatterOutermostFirst(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{{
+		WithMessage(io.EOF, "outer"),
+		"^outer\n" +
+			"EOF$",
+	}, {
+		Wrap(New("error"), "outer"),
+		"^outer\n" +
+			"github.com/pkg/errors.TestTextFormatterOutermostFirst\n" +
+			"\t.+/github.com/pkg/errors/formatter_test.go:107\n" +
+			"error\n" +
+			"github.com/pkg/errors.TestTextFormatterOutermostFirst\n" +
+			"\t.+/github.com/pkg/errors/formatter_test.go:107$",
+	}, {
+		WithMessage(WithStack(io.EOF), "outer"),
+		"^outer\n" +
+			"EOF\n" +
+			"github.com/pkg/errors.TestTextFormatterOutermostFirst\n" +
+			"\t.+/github.com/pkg/errors/formatter_test.go:115$",
+	}}
+
+	for i, tt := range tests {
+		testFormatRegexp(t, i, Format(tt.err, TextFormatter{Order: OutermostFirst}), "%+.1v", tt.want)
+	}
+}
+
+func TestFormatNil(t *testing.T) {
+	formatters := []Formatter{TextFormatter{}, TextFormatter{Order: OutermostFirst}, CompactFormatter{}, JSONFormatter{}, TreeFormatter{}, Normalize(nil)}
+	for _, f := range formatters {
+		for _, format := range []string{"%s", "%v", "%+v", "%q"} {
+			got := fmt.Sprintf(format, Format(nil, f))
+			want := fmt.Sprintf(format, error(nil))
+			if got != want {
+				t.Errorf("fmt.Sprintf(%q, Format(nil, %T)): got %q, want %q", format, f, got, want)
+			}
+		}
+	}
+	if got, want := fmt.Sprintf("%+v", Colorize(nil, DefaultColors)), "<nil>"; got != want {
+		t.Errorf("fmt.Sprintf(\"%%+v\", Colorize(nil, DefaultColors)): got %q, want %q", got, want)
+	}
+}
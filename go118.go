@@ -0,0 +1,29 @@
+//go:build go1.18
+// +build go1.18
+
+package errors
+
+// AsType finds the first error in err's tree, as walked by Find, that is
+// of type T, and returns it. An error also matches if it has a method
+// As(interface{}) bool such that As(&target) returns true for a target of
+// type T, in which case the target is returned.
+//
+// AsType avoids declaring a variable to pass to As:
+//
+//	if perr, ok := errors.AsType[*os.PathError](err); ok {
+//		fmt.Println(perr.Path)
+//	}
+func AsType[T error](err error) (T, bool) {
+	var target T
+	found := Find(err, func(err error) bool {
+		if t, ok := err.(T); ok {
+			target = t
+			return true
+		}
+		if x, ok := err.(interface{ As(interface{}) bool }); ok && x.As(&target) {
+			return true
+		}
+		return false
+	})
+	return target, found != nil
+}
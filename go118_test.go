@@ -0,0 +1,88 @@
+//go:build go1.18
+// +build go1.18
+
+package errors
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"testing"
+)
+
+// asErr converts itself to a customErr through its As method.
+type asErr struct{}
+
+func (asErr) Error() string { return "as" }
+
+func (asErr) As(target interface{}) bool {
+	if ce, ok := target.(*customErr); ok {
+		*ce = customErr{msg: "converted"}
+		return true
+	}
+	return false
+}
+
+func TestAsType(t *testing.T) {
+	err := customErr{msg: "test message"}
+
+	tests := []struct {
+		name string
+		err  error
+		want customErr
+		ok   bool
+	}{
+		{"nil", nil, customErr{}, false},
+		{"not found", io.EOF, customErr{}, false},
+		{"direct", err, err, true},
+		{"with stack", WithStack(err), err, true},
+		{"wrapped", Wrap(WithMessage(err, "inner"), "outer"), err, true},
+		{"std errors compatibility", fmt.Errorf("wrap it: %w", err), err, true},
+		{"tree", multiError{io.EOF, Wrap(err, "second")}, err, true},
+		{"cause only", foreignWrapper{"foreign", err}, err, true},
+		{"as method", Wrap(asErr{}, "wrapped"), customErr{msg: "converted"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := AsType[customErr](tt.err)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("AsType(%v) = %v, %v, want %v, %v", tt.err, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestAsTypeInterface(t *testing.T) {
+	err := Wrap(&os.PathError{Op: "open", Path: "x", Err: io.EOF}, "wrapped")
+	got, ok := AsType[interface {
+		error
+		Timeout() bool
+	}](err)
+	if !ok || got.Timeout() {
+		t.Errorf("AsType(%v) = %v, %v, want *os.PathError, true", err, got, ok)
+	}
+}
+
+func TestFind(t *testing.T) {
+	first, second := New("first"), New("second")
+	tests := []struct {
+		name  string
+		err   error
+		match func(error) bool
+		want  error
+	}{
+		{"nil", nil, func(error) bool { return true }, nil},
+		{"none", Wrap(io.EOF, "x"), func(error) bool { return false }, nil},
+		{"outermost", io.EOF, func(error) bool { return true }, io.EOF},
+		{"cause", Wrap(io.EOF, "x"), func(err error) bool { return err == io.EOF }, io.EOF},
+		{"tree", multiError{io.EOF, WithMessage(second, "x")}, func(err error) bool { return err.Error() == "second" }, second},
+		{"depth first", multiError{Wrap(first, "x"), second}, func(err error) bool { _, ok := err.(*fundamental); return ok }, first},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Find(tt.err, tt.match); got != tt.want {
+				t.Errorf("Find(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+)
+
+// Hint is advice for the user of a program on how to remedy an error,
+// attached by WithHint, WithHintf or WithDocURL.
+type Hint struct {
+	Text string // what to try, such as "run 'tool init' first"; may be empty
+	URL  string // documentation about the error; may be empty
+}
+
+// String returns h as printed by %+v and Report: "hint: " followed by
+// its Text, or "docs: " followed by its URL, or both on separate lines.
+func (h Hint) String() string {
+	switch {
+	case h.URL == "":
+		return "hint: " + h.Text
+	case h.Text == "":
+		return "docs: " + h.URL
+	}
+	return "hint: " + h.Text + "\ndocs: " + h.URL
+}
+
+// WithHint annotates err with advice for the user on how to remedy it.
+// The hint does not appear in err's message; %+v prints it in a section
+// after the chain, and Hints returns it.
+// If err is nil, WithHint returns nil.
+func WithHint(err error, hint string) error {
+	if err == nil {
+		return nil
+	}
+	return &withHint{
+		cause: err,
+		hint:  Hint{Text: hint},
+	}
+}
+
+// WithHintf annotates err with a hint formatted according to the format
+// specifier.
+// If err is nil, WithHintf returns nil.
+func WithHintf(err error, format string, args ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+	return &withHint{
+		cause: err,
+		hint:  Hint{Text: fmt.Sprintf(format, args...)},
+	}
+}
+
+// WithDocURL annotates err with the URL of documentation about it, which
+// is printed and returned as for WithHint.
+// If err is nil, WithDocURL returns nil.
+func WithDocURL(err error, url string) error {
+	if err == nil {
+		return nil
+	}
+	return &withHint{
+		cause: err,
+		hint:  Hint{URL: url},
+	}
+}
+
+type withHint struct {
+	cause error
+	hint  Hint
+}
+
+func (w *withHint) Error() string    { return w.cause.Error() }
+func (w *withHint) Cause() error     { return w.cause }
+func (w *withHint) Annotated() error { return w.cause }
+
+// Unwrap provides compatibility for Go 1.13 error chains.
+func (w *withHint) Unwrap() error { return w.cause }
+
+func (w *withHint) Format(s fmt.State, verb rune) {
+	currentFormatter().FormatError(s, verb, w)
+}
+
+// Hints returns the hints attached to err and the errors in its tree, in
+// the order of Layers, outermost first. A hint attached more than once is
+// returned once.
+func Hints(err error) []Hint {
+	var hints []Hint
+	seen := make(map[Hint]bool)
+	Layers(err)(func(l Layer) bool {
+		if w, ok := l.Err.(*withHint); ok && !seen[w.hint] {
+			seen[w.hint] = true
+			hints = append(hints, w.hint)
+		}
+		return true
+	})
+	return hints
+}
+
+// formatHints writes the hints attached in the chain of err to s in a
+// section of their own, if there are any, to follow its extended format.
+// Hints beneath an error not created by this package are left to its own
+// Format method, which prints them if it delegates to this package.
+func formatHints(s io.Writer, err error) {
+	var hints []Hint
+	seen := make(map[Hint]bool)
+	for _, e := range chain(err) {
+		if w, ok := e.(*withHint); ok && !seen[w.hint] {
+			seen[w.hint] = true
+			hints = append(hints, w.hint)
+		}
+	}
+	if len(hints) == 0 {
+		return
+	}
+	io.WriteString(s, "\n")
+	for _, h := range hints {
+		io.WriteString(s, "\n")
+		io.WriteString(s, h.String())
+	}
+}
+
+// Report writes err to w for the user of a command-line program: its
+// message on a line of its own, followed by a line for each of its hints,
+// without stack traces:
+//
+//	open tool.conf: no such file or directory
+//	hint: run 'tool init' first
+//	docs: https://example.com/tool/config
+//
+// Report does nothing if err is nil.
+func Report(w io.Writer, err error) {
+	if err == nil {
+		return
+	}
+	io.WriteString(w, err.Error())
+	for _, h := range Hints(err) {
+		io.WriteString(w, "\n")
+		io.WriteString(w, h.String())
+	}
+	io.WriteString(w, "\n")
+}
@@ -0,0 +1,105 @@
+package errors
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithHintNil(t *testing.T) {
+	if got := WithHint(nil, "no error"); got != nil {
+		t.Errorf("WithHint(nil, \"no error\"): got %#v, expected nil", got)
+	}
+	if got := WithHintf(nil, "no error %d", 1); got != nil {
+		t.Errorf("WithHintf(nil, \"no error %%d\", 1): got %#v, expected nil", got)
+	}
+	if got := WithDocURL(nil, "https://example.com"); got != nil {
+		t.Errorf("WithDocURL(nil, \"https://example.com\"): got %#v, expected nil", got)
+	}
+}
+
+func TestHints(t *testing.T) {
+	tests := []struct {
+		err  error
+		want []Hint
+	}{
+		{nil, nil},
+		{io.EOF, nil},
+		{WithHint(io.EOF, "retry"), []Hint{{Text: "retry"}}},
+		{WithDocURL(Wrap(WithHintf(io.EOF, "retry in %ds", 5), "read"), "https://example.com/eof"), []Hint{{URL: "https://example.com/eof"}, {Text: "retry in 5s"}}},
+		{WithHint(WithMessage(WithHint(io.EOF, "retry"), "read"), "retry"), []Hint{{Text: "retry"}}},
+		{multiError{WithHint(io.EOF, "first"), WithHint(io.EOF, "second")}, []Hint{{Text: "first"}, {Text: "second"}}},
+	}
+
+	for i, tt := range tests {
+		if got := Hints(tt.err); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("test %d: Hints(%v): got %v, want %v", i+1, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestHintString(t *testing.T) {
+	tests := []struct {
+		h    Hint
+		want string
+	}{
+		{Hint{Text: "retry"}, "hint: retry"},
+		{Hint{URL: "https://example.com"}, "docs: https://example.com"},
+		{Hint{Text: "retry", URL: "https://example.com"}, "hint: retry\ndocs: https://example.com"},
+	}
+
+	for i, tt := range tests {
+		if got := tt.h.String(); got != tt.want {
+			t.Errorf("test %d: got %q, want %q", i+1, got, tt.want)
+		}
+	}
+}
+
+func TestFormatWithHint(t *testing.T) {
+	err := WithDocURL(Wrap(WithHint(io.EOF, "check the file"), "read"), "https://example.com/eof")
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"%s", "^read: EOF$"},
+		{"%v", "^read: EOF$"},
+		{"%+v", "^EOF\n" +
+			"read\n" +
+			"github.com/pkg/errors.TestFormatWithHint\n" +
+			"\t.+/github.com/pkg/errors/hint_test.go:62$"},
+	}
+
+	for i, tt := range tests {
+		testFormatRegexp(t, i, err, tt.format, tt.want)
+	}
+
+	for _, f := range []Formatter{TextFormatter{}, TextFormatter{Order: OutermostFirst}} {
+		got := fmt.Sprintf("%+.1v", Format(err, f))
+		want := "\n\ndocs: https://example.com/eof\nhint: check the file"
+		if !strings.HasSuffix(got, want) {
+			t.Errorf("%+v: got %q, want suffix %q", f, got, want)
+		}
+	}
+}
+
+func TestReport(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{nil, ""},
+		{io.EOF, "EOF\n"},
+		{WithDocURL(Wrap(WithHint(io.EOF, "check the file"), "read"), "https://example.com/eof"), "read: EOF\ndocs: https://example.com/eof\nhint: check the file\n"},
+	}
+
+	for i, tt := range tests {
+		var b bytes.Buffer
+		Report(&b, tt.err)
+		if got := b.String(); got != tt.want {
+			t.Errorf("test %d: Report(%v): got %q, want %q", i+1, tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// Package printf parses and builds the formatting directives of the fmt
+// package, for the packages that format errors and the commands that check
+// and rewrite calls of Errorf and Wrapf.
+package printf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Verb is a formatting directive in a format string.
+type Verb struct {
+	Start, End int  // offsets of the directive, from its '%', in the format
+	Verb       rune // the verb, such as 'v' or 'w'
+	Arg        int  // index of its operand among the arguments after the format
+}
+
+// Parse returns the directives of format other than %%. It returns
+// false if format gives an explicit argument index, as in %[1]d, since the
+// operands of its verbs are then not followed.
+func Parse(format string) ([]Verb, bool) {
+	var vs []Verb
+	arg := 0
+	for i := 0; i < len(format); {
+		if format[i] != '%' {
+			i++
+			continue
+		}
+		start := i
+		i++
+		for i < len(format) && strings.IndexByte("+-# 0", format[i]) >= 0 {
+			i++
+		}
+		for _, part := range []bool{false, true} {
+			if part {
+				if i >= len(format) || format[i] != '.' {
+					break
+				}
+				i++
+			}
+			if i < len(format) && format[i] == '[' {
+				return nil, false
+			}
+			if i < len(format) && format[i] == '*' {
+				arg++
+				i++
+				continue
+			}
+			for i < len(format) && '0' <= format[i] && format[i] <= '9' {
+				i++
+			}
+		}
+		if i >= len(format) {
+			break
+		}
+		if format[i] == '[' {
+			return nil, false
+		}
+		r, size := utf8.DecodeRuneInString(format[i:])
+		i += size
+		if r == '%' {
+			continue
+		}
+		vs = append(vs, Verb{start, i, r, arg})
+		arg++
+	}
+	return vs, true
+}
+
+// Directive returns the directive, such as "%+.3v", that was formatted
+// with st and verb, so that a Format method can pass its flags, width and
+// precision on to another value.
+func Directive(st fmt.State, verb rune) string {
+	var b strings.Builder
+	b.WriteByte('%')
+	for _, flag := range "+-# 0" {
+		if st.Flag(int(flag)) {
+			b.WriteRune(flag)
+		}
+	}
+	if w, ok := st.Width(); ok {
+		b.WriteString(strconv.Itoa(w))
+	}
+	if p, ok := st.Precision(); ok {
+		b.WriteByte('.')
+		b.WriteString(strconv.Itoa(p))
+	}
+	b.WriteRune(verb)
+	return b.String()
+}
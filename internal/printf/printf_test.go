@@ -0,0 +1,46 @@
+package printf
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		format string
+		want   []Verb
+		ok     bool
+	}{
+		{"", nil, true},
+		{"100%%", nil, true},
+		{"%v", []Verb{{0, 2, 'v', 0}}, true},
+		{"open %s: %w", []Verb{{5, 7, 's', 0}, {9, 11, 'w', 1}}, true},
+		{"%+v %-8.3f", []Verb{{0, 3, 'v', 0}, {4, 10, 'f', 1}}, true},
+		{"%*d %.*s", []Verb{{0, 3, 'd', 1}, {4, 8, 's', 3}}, true},
+		{"%% %q", []Verb{{3, 5, 'q', 0}}, true},
+		{"%[1]v", nil, false},
+		{"%-[2]*d", nil, false},
+		{"trailing %", nil, true},
+	}
+
+	for _, tt := range tests {
+		got, ok := Parse(tt.format)
+		if !reflect.DeepEqual(got, tt.want) || ok != tt.ok {
+			t.Errorf("Parse(%q): got %v, %v, want %v, %v", tt.format, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// directive formats as the directive it was formatted with.
+type directive struct{}
+
+func (directive) Format(s fmt.State, verb rune) { fmt.Fprint(s, Directive(s, verb)) }
+
+func TestDirective(t *testing.T) {
+	for _, format := range []string{"%v", "%+v", "%#v", "%-8.3x", "% d", "%08q", "%.0s"} {
+		if got := fmt.Sprintf(format, directive{}); got != format {
+			t.Errorf("Directive for %q: got %q", format, got)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package errors
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Op names the operation that failed, such as "bucket.Put".
+type Op string
+
+// Path names the file, object or other resource on which an operation
+// failed.
+type Path string
+
+// Kind is the class of an error made by E, such as NotExist. It is
+// unrelated to LayerKind, which describes how a Layer was added to a chain.
+//
+// Kind implements error, so that
+//
+//	errors.Is(err, errors.NotExist)
+//
+// reports whether err's chain holds an *Error of Kind NotExist.
+type Kind uint8
+
+const (
+	Other      Kind = iota // unclassified error; not printed
+	Invalid                // invalid operation for this type of item
+	Permission             // permission denied
+	IO                     // external I/O error, such as a network failure
+	Exist                  // item already exists
+	NotExist               // item does not exist
+	NotEmpty               // item is not empty, as for a directory
+	Internal               // internal error or inconsistency
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Other:
+		return "other error"
+	case Invalid:
+		return "invalid operation"
+	case Permission:
+		return "permission denied"
+	case IO:
+		return "I/O error"
+	case Exist:
+		return "item already exists"
+	case NotExist:
+		return "item does not exist"
+	case NotEmpty:
+		return "item is not empty"
+	case Internal:
+		return "internal error"
+	}
+	return "unknown error kind"
+}
+
+func (k Kind) Error() string { return k.String() }
+
+// E returns an *Error built from its arguments, which may be given in any
+// order, each at most once:
+//
+//	Op    the operation that failed
+//	Kind  the class of the error
+//	Path  the resource on which the operation failed
+//	error the underlying error
+//
+// For example
+//
+//	errors.E(errors.Op("bucket.Put"), errors.NotExist, errors.Path("a/b"), err)
+//
+// E also records the stack trace at the point it was called. If the
+// error argument is nil, E returns nil. An argument of any other type
+// makes E return an error describing the bad call.
+func E(args ...interface{}) error {
+	if len(args) == 0 {
+		return Errorf("errors.E: no arguments")
+	}
+	e := &Error{stack: callers(), at: now()}
+	for _, arg := range args {
+		switch arg := arg.(type) {
+		case Op:
+			e.Op = arg
+		case Kind:
+			e.Kind = arg
+		case Path:
+			e.Path = arg
+		case error:
+			e.Err = arg
+		case nil:
+			return nil
+		default:
+			return Errorf("errors.E: bad argument of type %T: %v", arg, arg)
+		}
+	}
+	return e
+}
+
+// Error is an error made by E. Its fields are those given to E; each is
+// optional.
+type Error struct {
+	Op   Op
+	Kind Kind
+	Path Path
+	Err  error // the underlying error, or nil
+
+	stack *stack
+	at    *time.Time // when e was made, if timestamps are enabled
+}
+
+// Error returns e's Op, Path, Kind and the message of its underlying
+// error, separated by ": ". A Path or Kind that is the same as that of the
+// *Error that most closely wraps e is omitted, so that nested errors for
+// the same resource name it once.
+func (e *Error) Error() string { return e.text(nil, false) }
+
+// message returns e's own message, without that of its underlying error.
+func (e *Error) message() string {
+	var parts []string
+	if e.Op != "" {
+		parts = append(parts, string(e.Op))
+	}
+	if e.Path != "" {
+		parts = append(parts, string(e.Path))
+	}
+	if e.Kind != Other {
+		parts = append(parts, e.Kind.String())
+	}
+	return strings.Join(parts, ": ")
+}
+
+// text returns the message of e, omitting the Path and Kind it shares with
+// outer, the *Error that most closely wraps it, if not nil. If reveal is
+// true, Sensitive values are revealed as by Unredacted.
+func (e *Error) text(outer *Error, reveal bool) string {
+	var parts []string
+	if e.Op != "" {
+		parts = append(parts, string(e.Op))
+	}
+	if e.Path != "" && (outer == nil || outer.Path != e.Path) {
+		parts = append(parts, string(e.Path))
+	}
+	if e.Kind != Other && (outer == nil || outer.Kind != e.Kind) {
+		parts = append(parts, e.Kind.String())
+	}
+	if e.Err != nil {
+		if msg := causeText(e.Err, e, reveal); msg != "" {
+			parts = append(parts, msg)
+		}
+	}
+	return strings.Join(parts, ": ")
+}
+
+// causeText returns the message of err, wrapped by outer, looking through
+// the errors of this package for a nested *Error.
+func causeText(err error, outer *Error, reveal bool) string {
+	switch e := err.(type) {
+	case *Error:
+		return e.text(outer, reveal)
+	case *withMessage:
+		msg := e.message()
+		if reveal {
+			msg = e.deferred.revealed(e.msg)
+		}
+		return msg + ": " + causeText(e.cause, outer, reveal)
+	case *withStack:
+		return causeText(e.error, outer, reveal)
+	case Annotation:
+		return causeText(e.Annotated(), outer, reveal)
+	}
+	if reveal {
+		return Unredacted(err)
+	}
+	return err.Error()
+}
+
+func (e *Error) Cause() error { return e.Err }
+
+// Unwrap provides compatibility for Go 1.13 error chains.
+func (e *Error) Unwrap() error { return e.Err }
+
+// Is reports whether target is the Kind of e, other than Other.
+func (e *Error) Is(target error) bool {
+	k, ok := target.(Kind)
+	return ok && k != Other && e.Kind == k
+}
+
+// StackTrace returns the stack trace recorded by E, or nil if e was not
+// made by E.
+func (e *Error) StackTrace() StackTrace {
+	if e.stack == nil {
+		return nil
+	}
+	return e.stack.StackTrace()
+}
+
+func (e *Error) Format(s fmt.State, verb rune) {
+	currentFormatter().FormatError(s, verb, e)
+}
@@ -0,0 +1,135 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"testing"
+)
+
+func TestE(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{E(Op("bucket.Put")), "bucket.Put"},
+		{E(Op("bucket.Put"), NotExist, Path("a/b"), io.EOF), "bucket.Put: a/b: item does not exist: EOF"},
+		{E(io.EOF, Path("a/b"), Op("bucket.Put")), "bucket.Put: a/b: EOF"},
+		{E(Kind(Permission)), "permission denied"},
+
+		// The Path and Kind of the nearest *Error above are not repeated.
+		{E(Op("store.Save"), Path("a/b"), NotExist, E(Op("bucket.Put"), Path("a/b"), NotExist, io.EOF)), "store.Save: a/b: item does not exist: bucket.Put: EOF"},
+		{E(Op("store.Save"), Path("a"), E(Op("bucket.Put"), Path("a/b"), IO, io.EOF)), "store.Save: a: bucket.Put: a/b: I/O error: EOF"},
+		{E(Path("a"), Wrap(E(Path("a"), Invalid), "retry")), "a: retry: invalid operation"},
+		{E(Path("a"), WithMessage(E(Op("x"), Path("b"), E(Path("a"))), "m")), "a: m: x: b: a"},
+
+		// A nil error makes a nil *Error.
+		{E(Op("bucket.Put"), nil), ""},
+
+		// Bad calls.
+		{E(), "errors.E: no arguments"},
+		{E(Op("bucket.Put"), 3), "errors.E: bad argument of type int: 3"},
+	}
+
+	for i, tt := range tests {
+		got := ""
+		if tt.err != nil {
+			got = tt.err.Error()
+		}
+		if got != tt.want {
+			t.Errorf("test %d: got %q, want %q", i+1, got, tt.want)
+		}
+	}
+}
+
+func TestEIs(t *testing.T) {
+	tests := []struct {
+		err  error
+		kind Kind
+		want bool
+	}{
+		{E(NotExist), NotExist, true},
+		{E(NotExist), Exist, false},
+		{E(Op("x")), Other, false},
+		{Wrap(E(Permission, io.EOF), "read"), Permission, true},
+		{E(IO, E(NotExist)), NotExist, true},
+		{io.EOF, NotExist, false},
+	}
+
+	for i, tt := range tests {
+		if got := Is(tt.err, tt.kind); got != tt.want {
+			t.Errorf("test %d: Is(%v, %v): got %v, want %v", i+1, tt.err, tt.kind, got, tt.want)
+		}
+	}
+	if !Is(E(NotExist, io.EOF), io.EOF) {
+		t.Errorf("Is(E(NotExist, io.EOF), io.EOF): got false, want true")
+	}
+}
+
+func TestELayers(t *testing.T) {
+	err := E(Op("bucket.Put"), Path("a/b"), E(Path("a/b"), IO, io.EOF))
+	var got []Layer
+	Layers(err)(func(l Layer) bool {
+		got = append(got, l)
+		return true
+	})
+	want := []struct {
+		kind LayerKind
+		msg  string
+	}{
+		{MessageLayer, "bucket.Put: a/b"},
+		{MessageLayer, "a/b: I/O error"},
+		{ForeignLayer, "EOF"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Layers(%v): got %d layers, want %d", err, len(got), len(want))
+	}
+	for i, w := range want {
+		if got[i].Kind != w.kind || got[i].Message != w.msg {
+			t.Errorf("layer %d: got %v %q, want %v %q", i, got[i].Kind, got[i].Message, w.kind, w.msg)
+		}
+		if i < 2 && len(got[i].Stack) == 0 {
+			t.Errorf("layer %d: got no stack", i)
+		}
+	}
+}
+
+func TestFormatE(t *testing.T) {
+	err := E(Op("bucket.Put"), Path("a/b"), E(Path("a/b"), IO, io.EOF))
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"%s", "^bucket.Put: a/b: I/O error: EOF$"},
+		{"%v", "^bucket.Put: a/b: I/O error: EOF$"},
+		{"%q", `^"bucket.Put: a/b: I/O error: EOF"$`},
+		{"%+v", "^EOF\n" +
+			"a/b: I/O error\n" +
+			"github.com/pkg/errors.TestFormatE\n" +
+			"\t.+/github.com/pkg/errors/kind_test.go:98$"},
+	}
+
+	for i, tt := range tests {
+		testFormatRegexp(t, i, err, tt.format, tt.want)
+	}
+
+	if got, want := Unredacted(E(Op("login"), Wrapf(io.EOF, "user %s", Secret("bob")))), "login: user bob: EOF"; got != want {
+		t.Errorf("Unredacted: got %q, want %q", got, want)
+	}
+}
+
+func TestFormatEOutermostFirst(t *testing.T) {
+	err := E(Op("bucket.Put"), Path("a/b"), E(Path("a/b"), IO, io.EOF))
+	got := fmt.Sprintf("%+v", Format(err, TextFormatter{Order: OutermostFirst}))
+	want := regexp.MustCompile("^bucket.Put: a/b\n" +
+		"github.com/pkg/errors.TestFormatEOutermostFirst\n" +
+		"\t.+/github.com/pkg/errors/kind_test.go:\\d+\n" +
+		"(?s:.*)" +
+		"\na/b: I/O error\n" +
+		"github.com/pkg/errors.TestFormatEOutermostFirst\n" +
+		"(?s:.*)" +
+		"\nEOF$")
+	if !want.MatchString(got) {
+		t.Errorf("got %q, want match for %q", got, want)
+	}
+}
@@ -0,0 +1,151 @@
+package errors
+
+// LayerKind identifies what added a Layer to an error chain.
+type LayerKind int
+
+const (
+	// MessageLayer is a message added by WithMessage, WithMessagef, Wrap
+	// or Wrapf, or an *Error made by E, whose message is its Op, Path and
+	// Kind.
+	MessageLayer LayerKind = iota
+
+	// StackLayer is a stack trace added by WithStack, Wrap or Wrapf.
+	StackLayer
+
+	// FundamentalLayer is an error created by New or Errorf.
+	FundamentalLayer
+
+	// ForeignLayer is an error not created by this package.
+	ForeignLayer
+
+	// AnnotationLayer is an Annotation: an error that attaches data, such
+	// as a public message, without adding to the message of the error
+	// beneath it.
+	AnnotationLayer
+)
+
+func (k LayerKind) String() string {
+	switch k {
+	case MessageLayer:
+		return "message"
+	case StackLayer:
+		return "stack"
+	case FundamentalLayer:
+		return "fundamental"
+	case ForeignLayer:
+		return "foreign"
+	case AnnotationLayer:
+		return "annotation"
+	}
+	return "unknown"
+}
+
+// Annotation is implemented by an error that attaches data to another, its
+// only cause, without adding to its message or its extended format, as do
+// WithPublic, WithSeverity, WithHint and WithExitCode. Layers yields it as
+// an AnnotationLayer and the formatters of this package pass through it to
+// Annotated, so that an Annotation defined by another package does not hide
+// the structure of the errors beneath it. Annotated must not return nil.
+type Annotation interface {
+	error
+
+	// Annotated returns the error to which the data is attached.
+	Annotated() error
+}
+
+// Layer is a single error in an error chain or tree, as yielded by Layers.
+type Layer struct {
+	Err   error     // the error itself
+	Kind  LayerKind // what added the error
+	Depth int       // 0 for the error passed to Layers, 1 for its causes, and so on
+
+	// Message is the part of Err's message added by this layer, without
+	// the messages of its causes. It is empty for a StackLayer or an
+	// AnnotationLayer.
+	Message string
+
+	// Stack is the stack trace recorded by this layer, if any. A foreign
+	// layer has a Stack if its error has a StackTrace() StackTrace method.
+	Stack StackTrace
+}
+
+// Layers returns an iterator over the layers of err, outermost first,
+// suitable for use with a range statement:
+//
+//	for l := range errors.Layers(err) {
+//		fmt.Println(l.Depth, l.Kind, l.Message)
+//	}
+//
+// The layers beneath an error are found as described by Find, so an error
+// with several causes yields each of its causes' layers, depth first.
+func Layers(err error) func(yield func(Layer) bool) {
+	return func(yield func(Layer) bool) {
+		yieldLayers(err, 0, yield)
+	}
+}
+
+// yieldLayers calls yield for err, at depth, and the layers beneath it. It
+// returns false if yield did.
+func yieldLayers(err error, depth int, yield func(Layer) bool) bool {
+	if err == nil {
+		return true
+	}
+	l := Layer{Err: err, Depth: depth}
+	cs := causes(err)
+	switch e := err.(type) {
+	case *fundamental:
+		l.Kind, l.Message, l.Stack = FundamentalLayer, e.message(), e.StackTrace()
+	case *withStack:
+		l.Kind, l.Stack = StackLayer, e.StackTrace()
+	case *withMessage:
+		l.Kind, l.Message = MessageLayer, e.message()
+	case *Error:
+		l.Kind, l.Message, l.Stack = MessageLayer, e.message(), e.StackTrace()
+	case Annotation:
+		l.Kind = AnnotationLayer
+	default:
+		l.Kind, l.Message = ForeignLayer, ownMessage(err, cs)
+		if st, ok := err.(interface{ StackTrace() StackTrace }); ok {
+			l.Stack = st.StackTrace()
+		}
+	}
+	if !yield(l) {
+		return false
+	}
+	for _, c := range cs {
+		if !yieldLayers(c, depth+1, yield) {
+			return false
+		}
+	}
+	return true
+}
+
+// Messages returns the message added by each layer of err, outermost first,
+// so that for a chain built with Wrap and WithMessage the result joined with
+// ": " is err.Error(). Layers that add no message, such as those added by
+// WithStack, are skipped.
+func Messages(err error) []string {
+	var msgs []string
+	Layers(err)(func(l Layer) bool {
+		if l.Message != "" {
+			msgs = append(msgs, l.Message)
+		}
+		return true
+	})
+	return msgs
+}
+
+// Message returns the message added by the outermost layer of err that adds
+// one, without the messages of its causes. For example
+//
+//	errors.Message(errors.Wrap(io.EOF, "read failed"))
+//
+// returns "read failed". Message returns "" if err is nil.
+func Message(err error) string {
+	var msg string
+	Layers(err)(func(l Layer) bool {
+		msg = l.Message
+		return msg == ""
+	})
+	return msg
+}
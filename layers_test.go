@@ -0,0 +1,115 @@
+package errors
+
+import (
+	"io"
+	"reflect"
+	"testing"
+)
+
+// layer is the part of a Layer compared by TestLayers.
+type layer struct {
+	Kind    LayerKind
+	Depth   int
+	Message string
+	Stack   bool
+}
+
+func TestLayers(t *testing.T) {
+	tests := []struct {
+		err  error
+		want []layer
+	}{{
+		nil,
+		nil,
+	}, {
+		io.EOF,
+		[]layer{{ForeignLayer, 0, "EOF", false}},
+	}, {
+		New("error"),
+		[]layer{{FundamentalLayer, 0, "error", true}},
+	}, {
+		Wrap(WithMessage(io.EOF, "inner"), "outer"),
+		[]layer{
+			{StackLayer, 0, "", true},
+			{MessageLayer, 1, "outer", false},
+			{MessageLayer, 2, "inner", false},
+			{ForeignLayer, 3, "EOF", false},
+		},
+	}, {
+		WithMessage(multiError{foreignWrapper{"first", io.EOF}, New("second")}, "outer"),
+		[]layer{
+			{MessageLayer, 0, "outer", false},
+			{ForeignLayer, 1, "", false},
+			{ForeignLayer, 2, "first", false},
+			{ForeignLayer, 3, "EOF", false},
+			{FundamentalLayer, 2, "second", true},
+		},
+	}}
+
+	for i, tt := range tests {
+		var got []layer
+		Layers(tt.err)(func(l Layer) bool {
+			got = append(got, layer{l.Kind, l.Depth, l.Message, l.Stack != nil})
+			return true
+		})
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("test %d: Layers(%v):\n got: %v\nwant: %v", i+1, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestLayersStop(t *testing.T) {
+	err := multiError{Wrap(io.EOF, "first"), New("second")}
+	var n int
+	Layers(err)(func(l Layer) bool {
+		n++
+		return l.Kind != StackLayer
+	})
+	if n != 2 {
+		t.Errorf("Layers(%v): yield called %d times after returning false, want 2", err, n)
+	}
+}
+
+func TestLayerKindString(t *testing.T) {
+	tests := []struct {
+		LayerKind
+		want string
+	}{
+		{MessageLayer, "message"},
+		{StackLayer, "stack"},
+		{FundamentalLayer, "fundamental"},
+		{ForeignLayer, "foreign"},
+		{AnnotationLayer, "annotation"},
+		{LayerKind(-1), "unknown"},
+	}
+	for _, tt := range tests {
+		if got := tt.LayerKind.String(); got != tt.want {
+			t.Errorf("LayerKind(%d).String(): got %q, want %q", int(tt.LayerKind), got, tt.want)
+		}
+	}
+}
+
+func TestMessages(t *testing.T) {
+	tests := []struct {
+		err     error
+		want    []string
+		message string
+	}{
+		{nil, nil, ""},
+		{io.EOF, []string{"EOF"}, "EOF"},
+		{New("error"), []string{"error"}, "error"},
+		{WithStack(io.EOF), []string{"EOF"}, "EOF"},
+		{Wrap(WithMessage(io.EOF, "inner"), "outer"), []string{"outer", "inner", "EOF"}, "outer"},
+		{foreignWrapper{"foreign", Wrap(io.EOF, "inner")}, []string{"foreign", "inner", "EOF"}, "foreign"},
+		{multiError{io.EOF, New("second")}, []string{"EOF", "second"}, "EOF"},
+	}
+
+	for i, tt := range tests {
+		if got := Messages(tt.err); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("test %d: Messages(%v): got %q, want %q", i+1, tt.err, got, tt.want)
+		}
+		if got := Message(tt.err); got != tt.message {
+			t.Errorf("test %d: Message(%v): got %q, want %q", i+1, tt.err, got, tt.message)
+		}
+	}
+}
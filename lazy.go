@@ -0,0 +1,148 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// LazyErrorf is like Errorf, but defers formatting the message until it
+// is first printed, by Error or by the fmt package. This avoids the cost
+// of formatting for errors that are discarded or only compared with Is.
+// The message is formatted once, so args must not be modified after
+// LazyErrorf is called.
+// LazyErrorf also records the stack trace at the point it was called.
+func LazyErrorf(format string, args ...interface{}) error {
+	return &fundamental{
+		deferred: lazy(format, args),
+		stack:    callers(),
+		at:       now(),
+	}
+}
+
+// LazyWrapf is like Wrapf, but defers formatting the message until it is
+// first printed, as described by LazyErrorf.
+// If err is nil, LazyWrapf returns nil.
+func LazyWrapf(err error, format string, args ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+	err = &withMessage{
+		cause:    err,
+		deferred: lazy(format, args),
+	}
+	return &withStack{
+		err,
+		callers(),
+		now(),
+	}
+}
+
+// LazyWithMessagef is like WithMessagef, but defers formatting the message
+// until it is first printed, as described by LazyErrorf.
+// If err is nil, LazyWithMessagef returns nil.
+func LazyWithMessagef(err error, format string, args ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+	return &withMessage{
+		cause:    err,
+		deferred: lazy(format, args),
+	}
+}
+
+// deferred holds the format and arguments of a message that is formatted
+// when it is first printed rather than when its error is created, or the
+// message formatted from a Sensitive argument.
+type deferred struct {
+	format string
+	args   []interface{} // released once the message is formatted
+
+	once      sync.Once
+	sensitive bool   // set if args include a Sensitive value
+	text      string // the message, with Sensitive values redacted
+	secret    string // the message, with Sensitive values revealed
+}
+
+// sprintf formats a message from format and args. If args include a
+// Sensitive value, the message is formatted both with the value redacted
+// and with it revealed, to be chosen between when it is printed according
+// to the redaction then in effect.
+func sprintf(format string, args []interface{}) (string, *deferred) {
+	if sensitive(args) {
+		d := lazy(format, args)
+		d.formatted()
+		return "", d
+	}
+	return fmt.Sprintf(format, args...), nil
+}
+
+// lazy returns a deferred message formatted from format and args when it
+// is first printed.
+func lazy(format string, args []interface{}) *deferred {
+	return &deferred{format: format, args: args, sensitive: sensitive(args)}
+}
+
+// sensitive reports whether args include a Sensitive value.
+func sensitive(args []interface{}) bool {
+	for _, arg := range args {
+		if _, ok := arg.(Sensitive); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// formatted formats the message of d, if it has not been already, and
+// releases its arguments.
+func (d *deferred) formatted() {
+	d.once.Do(func() {
+		if !d.sensitive {
+			d.text = fmt.Sprintf(d.format, d.args...)
+		} else {
+			redacted := make([]interface{}, len(d.args))
+			revealed := make([]interface{}, len(d.args))
+			for i, arg := range d.args {
+				redacted[i], revealed[i] = arg, arg
+				if s, ok := arg.(Sensitive); ok {
+					redacted[i], revealed[i] = redactedValue{}, s.value
+				}
+			}
+			d.text = fmt.Sprintf(d.format, redacted...)
+			d.secret = fmt.Sprintf(d.format, revealed...)
+		}
+		d.args = nil
+	})
+}
+
+// message returns the message formatted by d, with any Sensitive values
+// redacted unless redaction is disabled, or msg if d is nil.
+func (d *deferred) message(msg string) string {
+	if d == nil {
+		return msg
+	}
+	d.formatted()
+	if d.sensitive && !redacting() {
+		return d.secret
+	}
+	return d.text
+}
+
+// revealed returns the message formatted by d with the value of each
+// Sensitive argument in place of the argument, or msg if d is nil.
+func (d *deferred) revealed(msg string) string {
+	if d == nil {
+		return msg
+	}
+	d.formatted()
+	if d.sensitive {
+		return d.secret
+	}
+	return d.text
+}
+
+// redactedValue prints as Redacted with any verb, whatever the redaction
+// in effect, in place of a Sensitive argument.
+type redactedValue struct{}
+
+func (redactedValue) Format(s fmt.State, verb rune) { io.WriteString(s, Redacted) }
@@ -0,0 +1,106 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+)
+
+// counter counts the number of times it is formatted.
+type counter struct {
+	mu sync.Mutex
+	n  int
+}
+
+func (c *counter) String() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.n++
+	return "counter"
+}
+
+func (c *counter) count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.n
+}
+
+func TestLazyNil(t *testing.T) {
+	if got := LazyWrapf(nil, "no error"); got != nil {
+		t.Errorf("LazyWrapf(nil, \"no error\"): got %#v, expected nil", got)
+	}
+	if got := LazyWithMessagef(nil, "no error"); got != nil {
+		t.Errorf("LazyWithMessagef(nil, \"no error\"): got %#v, expected nil", got)
+	}
+}
+
+func TestLazy(t *testing.T) {
+	tests := []struct {
+		err  func(c *counter) error
+		want string
+	}{{
+		func(c *counter) error { return LazyErrorf("%v %d", c, 1) },
+		"counter 1",
+	}, {
+		func(c *counter) error { return LazyWrapf(io.EOF, "%v %d", c, 2) },
+		"counter 2: EOF",
+	}, {
+		func(c *counter) error { return LazyWithMessagef(io.EOF, "%v %d", c, 3) },
+		"counter 3: EOF",
+	}}
+
+	for i, tt := range tests {
+		var c counter
+		err := tt.err(&c)
+		if c.count() != 0 {
+			t.Errorf("test %d: message formatted on creation", i+1)
+		}
+		if !Is(err, err) {
+			t.Errorf("test %d: Is(err, err): got false", i+1)
+		}
+		if c.count() != 0 {
+			t.Errorf("test %d: message formatted by Is", i+1)
+		}
+		var wg sync.WaitGroup
+		for j := 0; j < 4; j++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if got := err.Error(); got != tt.want {
+					t.Errorf("test %d: Error(): got %q, want %q", i+1, got, tt.want)
+				}
+			}()
+		}
+		wg.Wait()
+		if got := fmt.Sprintf("%v", err); got != tt.want {
+			t.Errorf("test %d: fmt.Sprintf(\"%%v\"): got %q, want %q", i+1, got, tt.want)
+		}
+		if got := c.count(); got != 1 {
+			t.Errorf("test %d: message formatted %d times, want 1", i+1, got)
+		}
+	}
+}
+
+func TestLazySensitive(t *testing.T) {
+	defer SetRedaction(true)
+
+	err := LazyWrapf(io.EOF, "user %s", Secret("alice"))
+	if got, want := err.Error(), "user [REDACTED]: EOF"; got != want {
+		t.Errorf("Error() redacted: got %q, want %q", got, want)
+	}
+	SetRedaction(false)
+	if got, want := err.Error(), "user alice: EOF"; got != want {
+		t.Errorf("Error() revealed: got %q, want %q", got, want)
+	}
+	SetRedaction(true)
+	if got, want := Unredacted(err), "user alice: EOF"; got != want {
+		t.Errorf("Unredacted(): got %q, want %q", got, want)
+	}
+}
+
+func TestFormatLazy(t *testing.T) {
+	testFormatRegexp(t, 0, LazyWrapf(LazyErrorf("error %d", 1), "wrapped %d", 2), "%+v", "^error 1\n"+
+		"github.com/pkg/errors.TestFormatLazy\n"+
+		"\t.+/github.com/pkg/errors/lazy_test.go:103")
+}
@@ -0,0 +1,40 @@
+//go:build go1.16
+// +build go1.16
+
+package locale
+
+import (
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// LoadFS reads the messages of each file in fsys matching pattern, such as
+// "messages/*.json" in an embed.FS, with LoadJSON. The language of each
+// file is its base name without its extension, so "messages/pt-BR.json"
+// holds the messages for "pt-BR".
+func (c *Messages) LoadFS(fsys fs.FS, pattern string) error {
+	names, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := c.loadFile(fsys, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Messages) loadFile(fsys fs.FS, name string) error {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	base := path.Base(name)
+	lang := strings.TrimSuffix(base, path.Ext(base))
+	return errors.Wrapf(c.LoadJSON(lang, f), "load %s", name)
+}
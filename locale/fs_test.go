@@ -0,0 +1,33 @@
+//go:build go1.16
+// +build go1.16
+
+package locale
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestMessagesLoadFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"messages/en.json":    {Data: []byte(`{"greeting": "hello"}`)},
+		"messages/pt-BR.json": {Data: []byte(`{"greeting": "olá"}`)},
+		"messages/README":     {Data: []byte(`not a catalog`)},
+	}
+	var c Messages
+	if err := c.LoadFS(fsys, "messages/*.json"); err != nil {
+		t.Fatal(err)
+	}
+	for lang, want := range map[string]string{"en": "hello", "pt-BR": "olá"} {
+		if got, ok := c.Message(lang, "greeting", nil); !ok || got != want {
+			t.Errorf("Message(%q, \"greeting\"): got %q, %v, want %q, true", lang, got, ok, want)
+		}
+	}
+
+	fsys["messages/fr.json"] = &fstest.MapFile{Data: []byte(`{`)}
+	err := c.LoadFS(fsys, "messages/*.json")
+	if err == nil || !strings.HasPrefix(err.Error(), "load messages/fr.json: ") {
+		t.Errorf("LoadFS with invalid file: got %v, want error loading messages/fr.json", err)
+	}
+}
@@ -0,0 +1,107 @@
+// Package locale renders the public messages attached to errors by
+// errors.WithPublicKey and errors.WithPublicParams in the language of
+// their reader.
+//
+// A message is identified by its key and looked up in a Catalog, first in
+// the requested language, such as "pt-BR", then in its base language,
+// "pt", and finally in Fallback. If no translation is found the message
+// given when the error was annotated is used. For example
+//
+//	err = errors.WithPublicParams(err, "quota.exceeded",
+//		map[string]interface{}{"count": n}, "quota exceeded")
+//	...
+//	msg, _ := locale.Localize(err, catalog, "fr")
+//
+// Parameters are substituted for their names in braces, so "{count}" is
+// replaced by the value of the "count" parameter, which also selects the
+// plural form of the message.
+package locale
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Fallback is the language in which messages are looked up when a Catalog
+// has none in the requested language.
+const Fallback = "en"
+
+// A Catalog supplies the translations of messages.
+type Catalog interface {
+	// Message returns the message for key in the language lang, with
+	// params substituted, and reports whether there is one. It does not
+	// fall back to other languages.
+	Message(lang, key string, params map[string]interface{}) (string, bool)
+}
+
+// Localize returns the public message of err, as found by
+// errors.PublicMessage, translated into lang by c. ok reports whether err
+// has a public message.
+func Localize(err error, c Catalog, lang string) (msg string, ok bool) {
+	msg, key, ok := errors.PublicMessage(err)
+	if !ok {
+		return "", false
+	}
+	params := errors.PublicParams(err)
+	if key != "" && c != nil {
+		for _, l := range languages(lang) {
+			if m, found := c.Message(l, key, params); found {
+				return m, true
+			}
+		}
+	}
+	return substitute(msg, params), true
+}
+
+// languages returns the languages in which to look up a message requested
+// in lang, most preferred first.
+func languages(lang string) []string {
+	var langs []string
+	add := func(l string) {
+		for _, seen := range langs {
+			if l == seen {
+				return
+			}
+		}
+		langs = append(langs, l)
+	}
+	if lang != "" {
+		add(lang)
+	}
+	if i := strings.IndexAny(lang, "-_"); i > 0 {
+		add(lang[:i])
+	}
+	add(Fallback)
+	return langs
+}
+
+// substitute replaces each {name} in text with the value of the parameter
+// name. References to unknown parameters are left unchanged.
+func substitute(text string, params map[string]interface{}) string {
+	if len(params) == 0 || !strings.Contains(text, "{") {
+		return text
+	}
+	var b strings.Builder
+	for {
+		i := strings.Index(text, "{")
+		if i < 0 {
+			break
+		}
+		j := strings.Index(text[i:], "}")
+		if j < 0 {
+			break
+		}
+		name := text[i+1 : i+j]
+		b.WriteString(text[:i])
+		if v, ok := params[name]; ok {
+			fmt.Fprint(&b, v)
+		} else {
+			b.WriteString(text[i : i+j+1])
+		}
+		text = text[i+j+1:]
+	}
+	b.WriteString(text)
+	return b.String()
+}
@@ -0,0 +1,95 @@
+package locale
+
+import (
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func testCatalog() *Messages {
+	var c Messages
+	c.Set("en", "not.found", Message{Other: "{name} was not found"})
+	c.Set("fr", "not.found", Message{Other: "{name} est introuvable"})
+	c.Set("pt", "not.found", Message{Other: "{name} não foi encontrado"})
+	c.Set("en", "quota", Message{One: "{count} file too many", Other: "{count} files too many"})
+	c.Set("fr", "quota", Message{One: "{count} fichier de trop", Other: "{count} fichiers de trop"})
+	return &c
+}
+
+func TestLocalize(t *testing.T) {
+	c := testCatalog()
+	notFound := errors.WithPublicParams(io.EOF, "not.found", map[string]interface{}{"name": "a.txt"}, "not found")
+	quota := func(n int) error {
+		return errors.Wrap(errors.WithPublicParams(io.EOF, "quota", map[string]interface{}{"count": n}, "quota exceeded"), "internal")
+	}
+	tests := []struct {
+		err  error
+		c    Catalog
+		lang string
+		want string
+		ok   bool
+	}{
+		{nil, c, "en", "", false},
+		{io.EOF, c, "en", "", false},
+		{notFound, c, "en", "a.txt was not found", true},
+		{notFound, c, "fr", "a.txt est introuvable", true},
+		{notFound, c, "pt-BR", "a.txt não foi encontrado", true},
+		{notFound, c, "de", "a.txt was not found", true},
+		{notFound, c, "", "a.txt was not found", true},
+		{notFound, nil, "fr", "not found", true},
+		{quota(1), c, "en", "1 file too many", true},
+		{quota(2), c, "en", "2 files too many", true},
+		{quota(0), c, "en", "0 files too many", true},
+		{quota(0), c, "fr", "0 fichier de trop", true},
+		{quota(2), c, "fr_CA", "2 fichiers de trop", true},
+		{errors.WithPublicKey(io.EOF, "unknown", "untranslated"), c, "fr", "untranslated", true},
+		{errors.WithPublic(io.EOF, "no key"), c, "fr", "no key", true},
+	}
+
+	for i, tt := range tests {
+		got, ok := Localize(tt.err, tt.c, tt.lang)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("test %d: Localize(%v, %q): got %q, %v, want %q, %v", i+1, tt.err, tt.lang, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestLanguages(t *testing.T) {
+	tests := []struct {
+		lang string
+		want []string
+	}{
+		{"", []string{"en"}},
+		{"en", []string{"en"}},
+		{"en-GB", []string{"en-GB", "en"}},
+		{"fr", []string{"fr", "en"}},
+		{"pt_BR", []string{"pt_BR", "pt", "en"}},
+	}
+	for _, tt := range tests {
+		if got := languages(tt.lang); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("languages(%q): got %q, want %q", tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestSubstitute(t *testing.T) {
+	params := map[string]interface{}{"name": "a.txt", "count": 3}
+	tests := []struct {
+		text, want string
+	}{
+		{"", ""},
+		{"no params", "no params"},
+		{"{name}", "a.txt"},
+		{"{count} copies of {name}", "3 copies of a.txt"},
+		{"{unknown} {name}", "{unknown} a.txt"},
+		{"{name", "{name"},
+		{"{}", "{}"},
+	}
+	for _, tt := range tests {
+		if got := substitute(tt.text, params); got != tt.want {
+			t.Errorf("substitute(%q): got %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package locale
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// Message is the text of a message in one language. A message that does
+// not vary with a count has only Other set; otherwise each plural form
+// used by the language is set, with Other used for any that is not.
+type Message struct {
+	Zero, One, Two, Few, Many, Other string
+}
+
+// form returns the text of m for the plural category.
+func (m Message) form(category string) string {
+	var text string
+	switch category {
+	case Zero:
+		text = m.Zero
+	case One:
+		text = m.One
+	case Two:
+		text = m.Two
+	case Few:
+		text = m.Few
+	case Many:
+		text = m.Many
+	}
+	if text == "" {
+		text = m.Other
+	}
+	return text
+}
+
+// UnmarshalJSON decodes a message from either a string, the text of a
+// message with no plural forms, or an object mapping plural categories to
+// text:
+//
+//	{"one": "{count} file", "other": "{count} files"}
+func (m *Message) UnmarshalJSON(b []byte) error {
+	var text string
+	if err := json.Unmarshal(b, &text); err == nil {
+		*m = Message{Other: text}
+		return nil
+	}
+	var forms struct {
+		Zero, One, Two, Few, Many, Other string
+	}
+	if err := json.Unmarshal(b, &forms); err != nil {
+		return err
+	}
+	*m = Message(forms)
+	return nil
+}
+
+// Messages is a Catalog held in memory. The zero value is an empty
+// catalog ready to use, and is safe for concurrent use.
+type Messages struct {
+	mu    sync.RWMutex
+	langs map[string]map[string]Message
+}
+
+// Set sets the message for key in the language lang.
+func (c *Messages) Set(lang, key string, m Message) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.langs == nil {
+		c.langs = make(map[string]map[string]Message)
+	}
+	if c.langs[lang] == nil {
+		c.langs[lang] = make(map[string]Message)
+	}
+	c.langs[lang][key] = m
+}
+
+// LoadJSON reads the messages of the language lang from r, a JSON object
+// mapping keys to messages as decoded by Message.UnmarshalJSON:
+//
+//	{
+//		"not.found": "{name} was not found",
+//		"quota.exceeded": {"one": "{count} file too many", "other": "{count} files too many"}
+//	}
+func (c *Messages) LoadJSON(lang string, r io.Reader) error {
+	var msgs map[string]Message
+	if err := json.NewDecoder(r).Decode(&msgs); err != nil {
+		return err
+	}
+	for key, m := range msgs {
+		c.Set(lang, key, m)
+	}
+	return nil
+}
+
+// Message implements Catalog. If params has a "count" parameter holding
+// an integer, it selects the plural form of the message.
+func (c *Messages) Message(lang, key string, params map[string]interface{}) (string, bool) {
+	c.mu.RLock()
+	m, ok := c.langs[lang][key]
+	c.mu.RUnlock()
+	if !ok {
+		return "", false
+	}
+	category := Other
+	if n, ok := count(params["count"]); ok {
+		category = PluralCategory(lang, n)
+	}
+	return substitute(m.form(category), params), true
+}
+
+// count returns v as an int if it holds an integer.
+func count(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int8:
+		return int(n), true
+	case int16:
+		return int(n), true
+	case int32:
+		return int(n), true
+	case int64:
+		return int(n), true
+	case uint:
+		return int(n), true
+	case uint8:
+		return int(n), true
+	case uint16:
+		return int(n), true
+	case uint32:
+		return int(n), true
+	case uint64:
+		return int(n), true
+	case float64:
+		if n == float64(int(n)) {
+			return int(n), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,66 @@
+package locale
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMessagesLoadJSON(t *testing.T) {
+	const input = `{
+		"not.found": "{name} was not found",
+		"quota": {"one": "{count} file", "other": "{count} files"}
+	}`
+	var c Messages
+	if err := c.LoadJSON("en", strings.NewReader(input)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		lang, key string
+		params    map[string]interface{}
+		want      string
+		ok        bool
+	}{
+		{"en", "not.found", map[string]interface{}{"name": "x"}, "x was not found", true},
+		{"en", "quota", map[string]interface{}{"count": 1}, "1 file", true},
+		{"en", "quota", map[string]interface{}{"count": int64(2)}, "2 files", true},
+		{"en", "quota", map[string]interface{}{"count": 1.0}, "1 file", true},
+		{"en", "quota", map[string]interface{}{"count": "1"}, "1 files", true},
+		{"en", "quota", nil, "{count} files", true},
+		{"en", "missing", nil, "", false},
+		{"fr", "quota", nil, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := c.Message(tt.lang, tt.key, tt.params)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Message(%q, %q, %v): got %q, %v, want %q, %v", tt.lang, tt.key, tt.params, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestMessagesLoadJSONError(t *testing.T) {
+	var c Messages
+	for _, input := range []string{`[]`, `{"key": 1}`, `{`} {
+		if err := c.LoadJSON("en", strings.NewReader(input)); err == nil {
+			t.Errorf("LoadJSON(%q): expected error", input)
+		}
+	}
+}
+
+func TestMessageForm(t *testing.T) {
+	m := Message{One: "one", Few: "few", Other: "other"}
+	tests := []struct {
+		category, want string
+	}{
+		{One, "one"},
+		{Few, "few"},
+		{Many, "other"},
+		{Zero, "other"},
+		{Other, "other"},
+	}
+	for _, tt := range tests {
+		if got := m.form(tt.category); got != tt.want {
+			t.Errorf("form(%q): got %q, want %q", tt.category, got, tt.want)
+		}
+	}
+}
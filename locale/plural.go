@@ -0,0 +1,117 @@
+package locale
+
+import (
+	"strings"
+)
+
+// The plural categories defined by the Unicode CLDR.
+const (
+	Zero  = "zero"
+	One   = "one"
+	Two   = "two"
+	Few   = "few"
+	Many  = "many"
+	Other = "other"
+)
+
+// pluralRules maps a base language to the function choosing the plural
+// category of a count in it. Languages not listed use pluralOne.
+var pluralRules = map[string]func(n int) string{
+	"fr": pluralFrench,
+	"pt": pluralFrench,
+	"ja": pluralNone,
+	"ko": pluralNone,
+	"zh": pluralNone,
+	"th": pluralNone,
+	"vi": pluralNone,
+	"id": pluralNone,
+	"ru": pluralSlavic,
+	"uk": pluralSlavic,
+	"be": pluralSlavic,
+	"pl": pluralPolish,
+	"cs": pluralCzech,
+	"sk": pluralCzech,
+	"ar": pluralArabic,
+}
+
+// PluralCategory returns the plural category, such as One or Other, of the
+// count n in the language lang. Languages with no rule of their own use
+// the rule of English.
+func PluralCategory(lang string, n int) string {
+	if i := strings.IndexAny(lang, "-_"); i > 0 {
+		lang = lang[:i]
+	}
+	if n < 0 {
+		n = -n
+	}
+	if rule, ok := pluralRules[strings.ToLower(lang)]; ok {
+		return rule(n)
+	}
+	return pluralOne(n)
+}
+
+func pluralOne(n int) string {
+	if n == 1 {
+		return One
+	}
+	return Other
+}
+
+func pluralFrench(n int) string {
+	if n == 0 || n == 1 {
+		return One
+	}
+	return Other
+}
+
+func pluralNone(int) string { return Other }
+
+func pluralSlavic(n int) string {
+	switch {
+	case n%10 == 1 && n%100 != 11:
+		return One
+	case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+		return Few
+	default:
+		return Many
+	}
+}
+
+func pluralPolish(n int) string {
+	switch {
+	case n == 1:
+		return One
+	case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+		return Few
+	default:
+		return Many
+	}
+}
+
+func pluralCzech(n int) string {
+	switch {
+	case n == 1:
+		return One
+	case n >= 2 && n <= 4:
+		return Few
+	default:
+		return Other
+	}
+}
+
+func pluralArabic(n int) string {
+	switch {
+	case n == 0:
+		return Zero
+	case n == 1:
+		return One
+	case n == 2:
+		return Two
+	case n%100 >= 3 && n%100 <= 10:
+		return Few
+	case n%100 >= 11:
+		return Many
+	default:
+		return Other
+	}
+}
@@ -0,0 +1,44 @@
+package locale
+
+import (
+	"testing"
+)
+
+func TestPluralCategory(t *testing.T) {
+	tests := []struct {
+		lang string
+		n    int
+		want string
+	}{
+		{"en", 0, Other},
+		{"en", 1, One},
+		{"en", -1, One},
+		{"en", 2, Other},
+		{"xx", 1, One},
+		{"fr", 0, One},
+		{"fr-CA", 1, One},
+		{"fr", 2, Other},
+		{"ja", 1, Other},
+		{"ru", 1, One},
+		{"ru", 21, One},
+		{"ru", 11, Many},
+		{"ru", 3, Few},
+		{"ru", 13, Many},
+		{"ru", 5, Many},
+		{"pl", 1, One},
+		{"pl", 21, Many},
+		{"pl", 22, Few},
+		{"cs", 3, Few},
+		{"cs", 5, Other},
+		{"ar", 0, Zero},
+		{"ar", 2, Two},
+		{"ar", 5, Few},
+		{"ar", 11, Many},
+		{"ar", 100, Other},
+	}
+	for _, tt := range tests {
+		if got := PluralCategory(tt.lang, tt.n); got != tt.want {
+			t.Errorf("PluralCategory(%q, %d): got %q, want %q", tt.lang, tt.n, got, tt.want)
+		}
+	}
+}
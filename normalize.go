@@ -0,0 +1,148 @@
+package errors
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Placeholders printed by a Formatter returned by Normalize.
+const (
+	// NormalizedLine replaces the line number of each frame.
+	NormalizedLine = "LINE"
+
+	// NormalizedAddress replaces hexadecimal addresses, such as program
+	// counters and pointers, in the output.
+	NormalizedAddress = "0xADDR"
+)
+
+// addresses matches the hexadecimal addresses replaced by NormalizedAddress.
+var addresses = regexp.MustCompile(`\b0x[0-9a-fA-F]{6,}\b`)
+
+// Normalize returns a Formatter that prints errors as f does, but in a
+// form that does not change from one machine, build or Go release to the
+// next, so that it can be compared with the output of an earlier run:
+//
+//   - the file of each frame is printed as its base name, without the
+//     directory that holds it;
+//   - the line of each frame is printed as NormalizedLine, and no source
+//     context is printed for %+#v;
+//   - frames in the standard library, such as those of the runtime and
+//     testing packages, and in the main function generated by go test are
+//     left out;
+//   - hexadecimal addresses anywhere in the output are printed as
+//     NormalizedAddress.
+//
+// A precision limits the frames that are printed, not those left out. If f
+// is nil, the Formatter installed with SetFormatter is used.
+func Normalize(f Formatter) Formatter {
+	return normalizer{f}
+}
+
+type normalizer struct {
+	f Formatter
+}
+
+// FormatError implements Formatter.
+func (n normalizer) FormatError(s fmt.State, verb rune, err error) {
+	f := n.f
+	if f == nil {
+		f = currentFormatter()
+	}
+	var b bytes.Buffer
+	f.FormatError(normalizing{s, &b}, verb, err)
+	s.Write(addresses.ReplaceAll(b.Bytes(), []byte(NormalizedAddress)))
+}
+
+// normalizing is the fmt.State given to the Formatter wrapped by Normalize.
+// It collects the output so that addresses can be replaced, and tells the
+// functions that print frames to normalize them.
+type normalizing struct {
+	fmt.State
+	b *bytes.Buffer
+}
+
+func (s normalizing) Write(b []byte) (int, error) { return s.b.Write(b) }
+
+// normalized reports whether frames printed to s should be normalized.
+func normalized(s fmt.State) bool {
+	_, ok := s.(normalizing)
+	return ok
+}
+
+// frames returns the frames of st to print to s, limited by any precision
+// and leaving out those that Normalize does not print.
+func frames(s fmt.State, st stack) []Frame {
+	fs := make([]Frame, 0, len(st))
+	for _, pc := range st {
+		f := Frame(pc)
+		if normalized(s) && !userFrame(f) {
+			continue
+		}
+		fs = append(fs, f)
+	}
+	return fs[:depth(s, len(fs))]
+}
+
+// position returns the file and line of f as printed to s.
+func position(s fmt.State, f Frame) (file, line string) {
+	if normalized(s) {
+		return path.Base(f.file()), NormalizedLine
+	}
+	return f.file(), strconv.Itoa(f.line())
+}
+
+// userFrame reports whether f is outside the standard library and the
+// main function generated by go test.
+func userFrame(f Frame) bool {
+	if path.Base(f.file()) == "_testmain.go" {
+		return false
+	}
+	name := f.name()
+	if name == "unknown" {
+		return true
+	}
+	if stdSrc != "" {
+		return !strings.HasPrefix(f.file(), stdSrc)
+	}
+
+	// Built with -trimpath, the files of the standard library are named by
+	// their import paths, so tell it by the first element of the import
+	// path of f's package, which has no dot.
+	pkg := name
+	if i := strings.LastIndex(pkg, "/"); i >= 0 {
+		pkg = pkg[i+1:]
+	}
+	if i := strings.Index(pkg, "."); i >= 0 {
+		pkg = name[:len(name)-len(pkg)+i]
+	}
+	if pkg == "main" {
+		return true
+	}
+	elem := pkg
+	if i := strings.Index(elem, "/"); i >= 0 {
+		elem = elem[:i]
+	}
+	return strings.Contains(elem, ".")
+}
+
+// stdSrc is the directory, ending in a slash, that holds the source files
+// of the standard library as named by its frames, or "" if the program was
+// built with -trimpath.
+var stdSrc = func() string {
+	fn := runtime.FuncForPC(reflect.ValueOf(strings.Index).Pointer())
+	if fn == nil {
+		return ""
+	}
+	file, _ := fn.FileLine(fn.Entry())
+	src := path.Dir(path.Dir(file))
+	if src == "." {
+		return ""
+	}
+	return src + "/"
+}()
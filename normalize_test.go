@@ -0,0 +1,109 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"testing"
+)
+
+// addressError is an error whose message contains an address.
+type addressError struct{}
+
+func (addressError) Error() string { return fmt.Sprintf("bad pointer %p", &addressError{}) }
+
+func normalizedFn() error {
+	return Wrap(New("inner"), "outer")
+}
+
+func TestNormalize(t *testing.T) {
+	err := normalizedFn()
+	tests := []struct {
+		f      Formatter
+		format string
+		want   string
+	}{
+		{TextFormatter{}, "%v", "outer: inner"},
+		{TextFormatter{}, "%+v", "inner\n" +
+			"github.com/pkg/errors.normalizedFn\n" +
+			"\tnormalize_test.go:LINE\n" +
+			"github.com/pkg/errors.TestNormalize\n" +
+			"\tnormalize_test.go:LINE\n" +
+			"outer\n" +
+			"github.com/pkg/errors.normalizedFn\n" +
+			"\tnormalize_test.go:LINE\n" +
+			"github.com/pkg/errors.TestNormalize\n" +
+			"\tnormalize_test.go:LINE"},
+		{TextFormatter{}, "%+.1v", "inner\n" +
+			"github.com/pkg/errors.normalizedFn\n" +
+			"\tnormalize_test.go:LINE\n" +
+			"outer\n" +
+			"github.com/pkg/errors.normalizedFn\n" +
+			"\tnormalize_test.go:LINE"},
+		{TextFormatter{}, "%+#.1v", "inner\n" +
+			"github.com/pkg/errors.normalizedFn\n" +
+			"\tnormalize_test.go:LINE\n" +
+			"outer\n" +
+			"github.com/pkg/errors.normalizedFn\n" +
+			"\tnormalize_test.go:LINE"},
+		{TreeFormatter{Stacks: true}, "%+.1v", "outer\n" +
+			"|  github.com/pkg/errors.normalizedFn\n" +
+			"|  \tnormalize_test.go:LINE\n" +
+			"`- inner\n" +
+			"      github.com/pkg/errors.normalizedFn\n" +
+			"      \tnormalize_test.go:LINE"},
+		{CompactFormatter{}, "%+v", "outer (normalize_test.go:LINE): inner (normalize_test.go:LINE)"},
+		{JSONFormatter{}, "%+.1v", `{"message":"outer","stack":["github.com/pkg/errors.normalizedFn normalize_test.go:LINE"]}` + "\n" +
+			`{"message":"inner","stack":["github.com/pkg/errors.normalizedFn normalize_test.go:LINE"]}`},
+		{TextFormatter{}, "%+.0v", "inner\nouter"},
+	}
+
+	for i, tt := range tests {
+		got := fmt.Sprintf(tt.format, Format(err, Normalize(tt.f)))
+		if got != tt.want {
+			t.Errorf("test %d: fmt.Sprintf(%q, Format(err, Normalize(%T))):\n got: %q\nwant: %q", i+1, tt.format, tt.f, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeAddress(t *testing.T) {
+	err := WithMessage(addressError{}, "read")
+	want := "bad pointer 0xADDR\nread"
+	if got := fmt.Sprintf("%+v", Format(err, Normalize(nil))); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestNormalizeCurrentFormatter(t *testing.T) {
+	defer SetFormatter(nil)
+	SetFormatter(CompactFormatter{})
+	err := WithStack(io.EOF)
+	want := "EOF (normalize_test.go:LINE)"
+	if got := fmt.Sprintf("%+v", Format(err, Normalize(nil))); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestUserFrame(t *testing.T) {
+	tests := []struct {
+		f    Frame
+		want bool
+	}{
+		{initpc, true},
+		{0, true},
+		{NewFrame("myapp/lib.Fail", "/src/myapp/lib/lib.go", 3), true},
+		{NewFrame("example/app.run", "/src/app/app.go", 7), true},
+		{NewFrame("strings.Index", stdSrc+"strings/strings.go", 1), false},
+	}
+	for i, tt := range tests {
+		if got := userFrame(tt.f); got != tt.want {
+			t.Errorf("test %d: userFrame(%v): got %v, want %v", i+1, tt.f, got, tt.want)
+		}
+	}
+	for _, pc := range *callers() {
+		f := Frame(pc)
+		want := f.name() == "github.com/pkg/errors.TestUserFrame"
+		if got := userFrame(f); got != want {
+			t.Errorf("userFrame(%+v): got %v, want %v", f, got, want)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package errors
+
+import (
+	"fmt"
+)
+
+// WithPublic annotates err with a message that is safe to show to the end
+// users of a program, as opposed to the internal detail in err's own
+// message. The public message does not appear in err's Error or formatted
+// output; it is retrieved with PublicMessage.
+// If err is nil, WithPublic returns nil.
+func WithPublic(err error, message string) error {
+	if err == nil {
+		return nil
+	}
+	return &withPublic{
+		cause: err,
+		msg:   message,
+	}
+}
+
+// WithPublicf annotates err with a public message formatted according to
+// the format specifier.
+// If err is nil, WithPublicf returns nil.
+func WithPublicf(err error, format string, args ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+	return &withPublic{
+		cause: err,
+		msg:   fmt.Sprintf(format, args...),
+	}
+}
+
+// WithPublicKey annotates err with a public message together with a key
+// identifying it, such as one used to look up a translation of message.
+// If err is nil, WithPublicKey returns nil.
+func WithPublicKey(err error, key, message string) error {
+	if err == nil {
+		return nil
+	}
+	return &withPublic{
+		cause: err,
+		msg:   message,
+		key:   key,
+	}
+}
+
+// WithPublicParams annotates err with a public message identified by key,
+// together with named parameters for use when rendering a translation of
+// it, as done by the locale package. message is the untranslated text.
+// If err is nil, WithPublicParams returns nil.
+func WithPublicParams(err error, key string, params map[string]interface{}, message string) error {
+	if err == nil {
+		return nil
+	}
+	return &withPublic{
+		cause:  err,
+		msg:    message,
+		key:    key,
+		params: params,
+	}
+}
+
+type withPublic struct {
+	cause  error
+	msg    string
+	key    string
+	params map[string]interface{}
+}
+
+func (w *withPublic) Error() string    { return w.cause.Error() }
+func (w *withPublic) Cause() error     { return w.cause }
+func (w *withPublic) Annotated() error { return w.cause }
+
+// Unwrap provides compatibility for Go 1.13 error chains.
+func (w *withPublic) Unwrap() error { return w.cause }
+
+func (w *withPublic) Format(s fmt.State, verb rune) {
+	currentFormatter().FormatError(s, verb, w)
+}
+
+// PublicMessage returns the most specific public message attached to err,
+// that is the one attached closest to the original cause, together with
+// its key, if any. ok reports whether a public message was found.
+func PublicMessage(err error) (message, key string, ok bool) {
+	if p := public(err); p != nil {
+		return p.msg, p.key, true
+	}
+	return "", "", false
+}
+
+// PublicParams returns the parameters attached with the public message
+// returned by PublicMessage, or nil if there are none.
+func PublicParams(err error) map[string]interface{} {
+	if p := public(err); p != nil {
+		return p.params
+	}
+	return nil
+}
+
+// public returns the withPublic in err's tree at the greatest depth,
+// preferring the first found, or nil if there is none.
+func public(err error) *withPublic {
+	var found *withPublic
+	depth := -1
+	Layers(err)(func(l Layer) bool {
+		if p, ok := l.Err.(*withPublic); ok && l.Depth > depth {
+			found, depth = p, l.Depth
+		}
+		return true
+	})
+	return found
+}
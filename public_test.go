@@ -0,0 +1,86 @@
+package errors
+
+import (
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestWithPublicNil(t *testing.T) {
+	if got := WithPublic(nil, "no error"); got != nil {
+		t.Errorf("WithPublic(nil, \"no error\"): got %#v, expected nil", got)
+	}
+	if got := WithPublicf(nil, "no error %d", 1); got != nil {
+		t.Errorf("WithPublicf(nil, \"no error %%d\", 1): got %#v, expected nil", got)
+	}
+	if got := WithPublicKey(nil, "key", "no error"); got != nil {
+		t.Errorf("WithPublicKey(nil, \"key\", \"no error\"): got %#v, expected nil", got)
+	}
+	if got := WithPublicParams(nil, "key", nil, "no error"); got != nil {
+		t.Errorf("WithPublicParams(nil, \"key\", nil, \"no error\"): got %#v, expected nil", got)
+	}
+}
+
+func TestPublicMessage(t *testing.T) {
+	tests := []struct {
+		err     error
+		message string
+		key     string
+		ok      bool
+	}{
+		{nil, "", "", false},
+		{io.EOF, "", "", false},
+		{Wrap(io.EOF, "internal"), "", "", false},
+		{WithPublic(io.EOF, "try again"), "try again", "", true},
+		{Wrap(WithPublicf(io.EOF, "try again in %d seconds", 5), "internal"), "try again in 5 seconds", "", true},
+		{WithPublic(WithPublicKey(io.EOF, "eof", "end of file"), "outer"), "end of file", "eof", true},
+		{multiError{io.EOF, WithMessage(WithPublic(io.EOF, "deep"), "x"), WithPublic(io.EOF, "shallow")}, "deep", "", true},
+	}
+
+	for i, tt := range tests {
+		message, key, ok := PublicMessage(tt.err)
+		if message != tt.message || key != tt.key || ok != tt.ok {
+			t.Errorf("test %d: PublicMessage(%v): got %q, %q, %v, want %q, %q, %v", i+1, tt.err, message, key, ok, tt.message, tt.key, tt.ok)
+		}
+	}
+}
+
+func TestFormatWithPublic(t *testing.T) {
+	err := WithPublic(Wrap(WithPublic(io.EOF, "public"), "internal"), "public")
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"%s", "^internal: EOF$"},
+		{"%v", "^internal: EOF$"},
+		{"%q", `^"internal: EOF"$`},
+		{"%+v", "^EOF\n" +
+			"internal\n" +
+			"github.com/pkg/errors.TestFormatWithPublic\n" +
+			"\t.+/github.com/pkg/errors/public_test.go:49$"},
+	}
+
+	for i, tt := range tests {
+		testFormatRegexp(t, i, err, tt.format, tt.want)
+	}
+}
+
+func TestPublicParams(t *testing.T) {
+	params := map[string]interface{}{"count": 3}
+	tests := []struct {
+		err  error
+		want map[string]interface{}
+	}{
+		{nil, nil},
+		{WithPublic(io.EOF, "no params"), nil},
+		{WithPublicParams(io.EOF, "files", params, "3 files"), params},
+		{WithPublic(Wrap(WithPublicParams(io.EOF, "files", params, "3 files"), "x"), "outer"), params},
+		{WithPublicParams(WithPublic(io.EOF, "inner"), "files", params, "3 files"), nil},
+	}
+
+	for i, tt := range tests {
+		if got := PublicParams(tt.err); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("test %d: PublicParams(%v): got %v, want %v", i+1, tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/pkg/errors/internal/printf"
+)
+
+// Redacted is printed in place of a Sensitive value while redaction is
+// enabled.
+const Redacted = "[REDACTED]"
+
+// reveal is non-zero if redaction has been disabled with SetRedaction.
+var reveal int32
+
+// SetRedaction enables or disables the redaction of Sensitive values.
+// Redaction is enabled by default. SetRedaction is safe to call
+// concurrently with formatting, and takes effect for errors created
+// before it was called.
+func SetRedaction(enabled bool) {
+	var v int32
+	if !enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&reveal, v)
+}
+
+// redacting reports whether Sensitive values are redacted.
+func redacting() bool { return atomic.LoadInt32(&reveal) == 0 }
+
+// Sensitive is a value, such as a password or an email address, that must
+// not appear in logs. While redaction is enabled it prints as Redacted
+// with any verb and encodes to JSON as Redacted; otherwise it prints and
+// encodes as its value. Use Secret to mark an argument of Errorf, Wrapf or
+// WithMessagef as sensitive:
+//
+//	return errors.Wrapf(err, "login failed for %s", errors.Secret(email))
+type Sensitive struct {
+	value interface{}
+}
+
+// Secret marks v as sensitive.
+func Secret(v interface{}) Sensitive { return Sensitive{v} }
+
+// Value returns the value marked as sensitive, for output that is
+// authorised to contain it.
+func (s Sensitive) Value() interface{} { return s.value }
+
+// Format formats s according to the fmt.Formatter interface.
+func (s Sensitive) Format(st fmt.State, verb rune) {
+	if redacting() {
+		st.Write([]byte(Redacted))
+		return
+	}
+	fmt.Fprintf(st, printf.Directive(st, verb), s.value)
+}
+
+// String returns Redacted, or the value printed with %v if redaction is
+// disabled.
+func (s Sensitive) String() string { return fmt.Sprint(s) }
+
+// MarshalJSON encodes s as Redacted, or as its value if redaction is
+// disabled.
+func (s Sensitive) MarshalJSON() ([]byte, error) {
+	if redacting() {
+		return json.Marshal(Redacted)
+	}
+	return json.Marshal(s.value)
+}
+
+// Unredacted returns the message of err, as returned by its Error method,
+// with the values of any Sensitive arguments given to Errorf, Wrapf or
+// WithMessagef in its chain regardless of redaction. It is intended for
+// debugging output that is authorised to contain them.
+func Unredacted(err error) string {
+	switch e := err.(type) {
+	case nil:
+		return ""
+	case *fundamental:
+		return e.deferred.revealed(e.msg)
+	case *withMessage:
+		return e.deferred.revealed(e.msg) + ": " + Unredacted(e.cause)
+	case *withStack:
+		return Unredacted(e.error)
+	case *Error:
+		return e.text(nil, true)
+	case Annotation:
+		return Unredacted(e.Annotated())
+	default:
+		return err.Error()
+	}
+}
@@ -0,0 +1,144 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestSensitiveFormat(t *testing.T) {
+	defer SetRedaction(true)
+
+	tests := []struct {
+		format   string
+		redacted string
+		revealed string
+	}{
+		{"%v", "[REDACTED]", "42"},
+		{"%d", "[REDACTED]", "42"},
+		{"%5d", "[REDACTED]", "   42"},
+		{"%-5d|", "[REDACTED]|", "42   |"},
+		{"%x", "[REDACTED]", "2a"},
+		{"%#x", "[REDACTED]", "0x2a"},
+		{"%+d", "[REDACTED]", "+42"},
+	}
+	for _, tt := range tests {
+		SetRedaction(true)
+		if got := fmt.Sprintf(tt.format, Secret(42)); got != tt.redacted {
+			t.Errorf("fmt.Sprintf(%q, Secret(42)) redacted: got %q, want %q", tt.format, got, tt.redacted)
+		}
+		SetRedaction(false)
+		if got := fmt.Sprintf(tt.format, Secret(42)); got != tt.revealed {
+			t.Errorf("fmt.Sprintf(%q, Secret(42)) revealed: got %q, want %q", tt.format, got, tt.revealed)
+		}
+	}
+}
+
+func TestSensitiveJSON(t *testing.T) {
+	defer SetRedaction(true)
+
+	v := struct {
+		Email Sensitive `json:"email"`
+	}{Secret("a@example.com")}
+
+	SetRedaction(true)
+	got, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"email":"[REDACTED]"}`; string(got) != want {
+		t.Errorf("json.Marshal redacted: got %s, want %s", got, want)
+	}
+
+	SetRedaction(false)
+	got, err = json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"email":"a@example.com"}`; string(got) != want {
+		t.Errorf("json.Marshal revealed: got %s, want %s", got, want)
+	}
+}
+
+func TestSensitiveValue(t *testing.T) {
+	s := Secret("token")
+	if got := s.Value(); got != "token" {
+		t.Errorf("Secret(\"token\").Value(): got %v, want \"token\"", got)
+	}
+	if got := s.String(); got != Redacted {
+		t.Errorf("Secret(\"token\").String(): got %q, want %q", got, Redacted)
+	}
+}
+
+func TestRedactErrors(t *testing.T) {
+	defer SetRedaction(true)
+
+	tests := []struct {
+		err      error
+		redacted string
+		revealed string
+	}{{
+		Errorf("bad token %s", Secret("t0k3n")),
+		"bad token [REDACTED]",
+		"bad token t0k3n",
+	}, {
+		Wrapf(io.EOF, "read %s for %s", "a.txt", Secret("a@example.com")),
+		"read a.txt for [REDACTED]: EOF",
+		"read a.txt for a@example.com: EOF",
+	}, {
+		WithMessagef(Errorf("user %d", Secret(7)), "login %s", Secret("alice")),
+		"login [REDACTED]: user [REDACTED]",
+		"login alice: user 7",
+	}, {
+		WithPublic(WithStack(Errorf("%s", Secret("x"))), "public"),
+		"[REDACTED]",
+		"x",
+	}, {
+		Wrapf(io.EOF, "plain %s", "arg"),
+		"plain arg: EOF",
+		"plain arg: EOF",
+	}}
+
+	for i, tt := range tests {
+		SetRedaction(true)
+		if got := tt.err.Error(); got != tt.redacted {
+			t.Errorf("test %d: Error() redacted: got %q, want %q", i+1, got, tt.redacted)
+		}
+		if got := Unredacted(tt.err); got != tt.revealed {
+			t.Errorf("test %d: Unredacted(): got %q, want %q", i+1, got, tt.revealed)
+		}
+		SetRedaction(false)
+		if got := tt.err.Error(); got != tt.revealed {
+			t.Errorf("test %d: Error() revealed: got %q, want %q", i+1, got, tt.revealed)
+		}
+	}
+}
+
+func TestFormatRedacted(t *testing.T) {
+	err := Wrapf(Errorf("token %s", Secret("t0k3n")), "user %s", Secret("alice"))
+	testFormatRegexp(t, 0, err, "%+v", "^token \\[REDACTED\\]\n"+
+		"github.com/pkg/errors.TestFormatRedacted\n"+
+		"\t.+/github.com/pkg/errors/redact_test.go:119")
+	testFormatRegexp(t, 1, Format(err, JSONFormatter{}), "%+v", `^{"message":"user \[REDACTED\]"`)
+	if got := Unredacted(nil); got != "" {
+		t.Errorf("Unredacted(nil): got %q, want \"\"", got)
+	}
+}
+
+func TestRedactFormatsOnCreation(t *testing.T) {
+	defer SetRedaction(true)
+
+	items := []string{"a"}
+	err := Errorf("user %s items %v", Secret("x"), items)
+	items[0] = "changed"
+
+	SetRedaction(true)
+	if got, want := err.Error(), "user [REDACTED] items [a]"; got != want {
+		t.Errorf("Error() redacted: got %q, want %q", got, want)
+	}
+	SetRedaction(false)
+	if got, want := err.Error(), "user x items [a]"; got != want {
+		t.Errorf("Error() revealed: got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,228 @@
+// Package retry calls a function until it succeeds, waiting longer between
+// each attempt, as decided by the Class of the errors it returns:
+//
+//	err := retry.Do(ctx, func() error {
+//		return upload(ctx, blob)
+//	}, retry.DefaultPolicy)
+//
+// Retrying stops when the function fails with an error that the classify
+// package reports as permanent, when the Policy allows no more attempts or
+// when the context is done. The delay before an attempt grows
+// exponentially, with jitter, and is at least that reported by
+// classify.RetryAfter for the error of the attempt before it.
+package retry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/pkg/errors/classify"
+)
+
+// Policy decides how often, and how long apart, Do calls its function.
+type Policy struct {
+	// MaxAttempts is the greatest number of times the function is
+	// called. Zero or less means there is no limit, in which case
+	// InitialDelay must be positive.
+	MaxAttempts int
+
+	// InitialDelay is the delay before the second attempt.
+	InitialDelay time.Duration
+
+	// MaxDelay limits the delay before any attempt, except that asked for
+	// by an error's RetryAfter. Zero or less means there is no limit.
+	MaxDelay time.Duration
+
+	// Multiplier is the factor by which the delay grows after each
+	// attempt. Values less than 1 are treated as 1, a constant delay.
+	Multiplier float64
+
+	// Jitter is the fraction, between 0 and 1, by which each delay is
+	// randomly lengthened or shortened, so that clients that failed
+	// together do not retry together.
+	Jitter float64
+
+	// Clock waits between attempts. If nil, the system clock is used.
+	Clock Clock
+
+	// Rand returns the random numbers, in [0, 1), that decide the jitter.
+	// If nil, math/rand.Float64 is used.
+	Rand func() float64
+}
+
+// DefaultPolicy makes up to five attempts, starting 100ms apart and
+// doubling the delay each time, with 20% jitter.
+var DefaultPolicy = Policy{
+	MaxAttempts:  5,
+	InitialDelay: 100 * time.Millisecond,
+	MaxDelay:     10 * time.Second,
+	Multiplier:   2,
+	Jitter:       0.2,
+}
+
+// A Clock waits between attempts. Tests may supply a Clock that returns
+// at once, recording the delays asked of it.
+type Clock interface {
+	// Sleep waits until d has passed, or ctx is done, in which case it
+	// returns ctx.Err().
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+type systemClock struct{}
+
+func (systemClock) Sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Do calls fn until it returns nil, following p, and returns nil if it
+// did. Otherwise Do returns an *Error holding the error of every attempt,
+// with a stack trace recorded by Do if it had none. fn is not called if
+// ctx is already done, or if p has neither MaxAttempts nor InitialDelay,
+// which would retry without pause for ever; Do then returns an error
+// describing p.
+func Do(ctx context.Context, fn func() error, p Policy) error {
+	if p.MaxAttempts <= 0 && p.InitialDelay <= 0 {
+		return errors.New("retry: Policy without MaxAttempts has no InitialDelay")
+	}
+	clock := p.Clock
+	if clock == nil {
+		clock = systemClock{}
+	}
+	e := new(Error)
+	for attempt := 1; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			e.Context = err
+			return e
+		}
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if !hasStack(err) {
+			err = errors.WithStack(err)
+		}
+		e.Attempts = append(e.Attempts, err)
+		if classify.IsPermanent(err) || p.MaxAttempts > 0 && attempt >= p.MaxAttempts {
+			return e
+		}
+		d := p.delay(attempt)
+		if after, ok := classify.RetryAfter(err); ok && after > d {
+			d = after
+		}
+		if err := clock.Sleep(ctx, d); err != nil {
+			e.Context = err
+			return e
+		}
+	}
+}
+
+// hasStack reports whether err or any error in its chain has a stack
+// trace.
+func hasStack(err error) bool {
+	var st interface{ StackTrace() errors.StackTrace }
+	return errors.As(err, &st)
+}
+
+// delay returns the delay after the given attempt, counted from 1.
+func (p Policy) delay(attempt int) time.Duration {
+	m := p.Multiplier
+	if m < 1 {
+		m = 1
+	}
+	d := float64(p.InitialDelay) * math.Pow(m, float64(attempt-1))
+	if p.Jitter > 0 {
+		r := rand.Float64
+		if p.Rand != nil {
+			r = p.Rand
+		}
+		d *= 1 + math.Min(p.Jitter, 1)*(2*r()-1)
+	}
+	if p.MaxDelay > 0 && d > float64(p.MaxDelay) {
+		return p.MaxDelay
+	}
+	if d >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return time.Duration(d)
+}
+
+// Error is the error returned by Do when its function did not succeed.
+// Its causes are the errors of the attempts, followed by that of the
+// context if it ended them, so errors.Is and errors.As find any of them,
+// and its Class is decided by them as for a joined error.
+type Error struct {
+	// Attempts holds the error of each attempt, in order.
+	Attempts []error
+
+	// Context is the error of the context if it was done before the
+	// attempts succeeded, or nil.
+	Context error
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	if n := len(e.Attempts); n == 1 {
+		b.WriteString("1 attempt failed")
+	} else {
+		fmt.Fprintf(&b, "%d attempts failed", n)
+	}
+	if e.Context != nil {
+		fmt.Fprintf(&b, " (%v)", e.Context)
+	}
+	if n := len(e.Attempts); n > 0 {
+		fmt.Fprintf(&b, ": %v", e.Attempts[n-1])
+	}
+	return b.String()
+}
+
+// Cause returns the error of the last attempt, or that of the context if
+// there was no attempt.
+func (e *Error) Cause() error {
+	if n := len(e.Attempts); n > 0 {
+		return e.Attempts[n-1]
+	}
+	return e.Context
+}
+
+// Unwrap returns the errors of the attempts, followed by that of the
+// context if it is not nil.
+func (e *Error) Unwrap() []error {
+	errs := append([]error(nil), e.Attempts...)
+	if e.Context != nil {
+		errs = append(errs, e.Context)
+	}
+	return errs
+}
+
+// Format formats e according to the fmt.Formatter interface. %+v prints
+// the error of every attempt in the extended format, with its stack trace.
+func (e *Error) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			io.WriteString(s, e.Error())
+			for i, err := range e.Attempts {
+				fmt.Fprintf(s, "\nattempt %d: %+v", i+1, err)
+			}
+			return
+		}
+		fallthrough
+	case 's':
+		io.WriteString(s, e.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	}
+}
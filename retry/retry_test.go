@@ -0,0 +1,242 @@
+package retry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/pkg/errors/classify"
+)
+
+// fakeClock is a Clock that returns at once, recording the delays asked of
+// it, and cancels a context after a number of sleeps.
+type fakeClock struct {
+	slept  []time.Duration
+	cancel func()
+	after  int
+}
+
+func (c *fakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	c.slept = append(c.slept, d)
+	if c.cancel != nil && len(c.slept) == c.after {
+		c.cancel()
+	}
+	return ctx.Err()
+}
+
+// attempts returns a function that returns each of errs in turn, then nil.
+func attempts(errs ...error) (func() error, *int) {
+	n := new(int)
+	return func() error {
+		*n++
+		if *n > len(errs) {
+			return nil
+		}
+		return errs[*n-1]
+	}, n
+}
+
+// half returns 0.5, for which there is no jitter.
+func half() float64 { return 0.5 }
+
+var policy = Policy{
+	MaxAttempts:  4,
+	InitialDelay: time.Second,
+	MaxDelay:     3 * time.Second,
+	Multiplier:   2,
+	Jitter:       0.5,
+	Rand:         half,
+}
+
+func TestDo(t *testing.T) {
+	tests := []struct {
+		errs     []error
+		calls    int
+		slept    []time.Duration
+		attempts int
+	}{{
+		errs:  nil,
+		calls: 1,
+	}, {
+		errs:  []error{io.EOF, io.EOF},
+		calls: 3,
+		slept: []time.Duration{time.Second, 2 * time.Second},
+	}, {
+		errs:     []error{io.EOF, io.EOF, io.EOF, io.EOF, io.EOF},
+		calls:    4,
+		slept:    []time.Duration{time.Second, 2 * time.Second, 3 * time.Second},
+		attempts: 4,
+	}, {
+		// A permanent error ends the attempts.
+		errs:     []error{io.EOF, classify.Permanent(io.EOF), io.EOF},
+		calls:    2,
+		slept:    []time.Duration{time.Second},
+		attempts: 2,
+	}, {
+		// A longer RetryAfter is honoured, even beyond MaxDelay.
+		errs:  []error{classify.Throttled(io.EOF, time.Minute), classify.Throttled(io.EOF, time.Millisecond)},
+		calls: 3,
+		slept: []time.Duration{time.Minute, 2 * time.Second},
+	}}
+
+	for i, tt := range tests {
+		clock := new(fakeClock)
+		p := policy
+		p.Clock = clock
+		fn, calls := attempts(tt.errs...)
+		err := Do(context.Background(), fn, p)
+		if *calls != tt.calls {
+			t.Errorf("test %d: calls: got %d, want %d", i+1, *calls, tt.calls)
+		}
+		if !reflect.DeepEqual(clock.slept, tt.slept) {
+			t.Errorf("test %d: slept: got %v, want %v", i+1, clock.slept, tt.slept)
+		}
+		if tt.attempts == 0 {
+			if err != nil {
+				t.Errorf("test %d: got %v, want nil", i+1, err)
+			}
+			continue
+		}
+		e, ok := err.(*Error)
+		if !ok {
+			t.Errorf("test %d: got %#v, want *Error", i+1, err)
+			continue
+		}
+		if len(e.Attempts) != tt.attempts {
+			t.Errorf("test %d: attempts: got %v, want %v", i+1, e.Attempts, tt.errs[:tt.attempts])
+			continue
+		}
+		for j, err := range e.Attempts {
+			if !errors.Is(err, tt.errs[j]) || !hasStack(err) {
+				t.Errorf("test %d: attempt %d: got %#v, want %v with a stack trace", i+1, j+1, err, tt.errs[j])
+			}
+		}
+	}
+}
+
+func TestDoBusyPolicy(t *testing.T) {
+	fn, calls := attempts(io.EOF)
+	err := Do(context.Background(), fn, Policy{})
+	if *calls != 0 {
+		t.Errorf("calls: got %d, want 0", *calls)
+	}
+	if err == nil {
+		t.Errorf("got nil, want an error")
+	}
+}
+
+func TestDoContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	clock := &fakeClock{cancel: cancel, after: 2}
+	p := policy
+	p.Clock = clock
+	p.MaxAttempts = 0
+	fn, calls := attempts(io.EOF, io.EOF, io.EOF, io.EOF)
+	err := Do(ctx, fn, p)
+	if *calls != 2 {
+		t.Errorf("calls: got %d, want 2", *calls)
+	}
+	if !errors.Is(err, context.Canceled) || !errors.Is(err, io.EOF) {
+		t.Errorf("got %v, want an error that is context.Canceled and io.EOF", err)
+	}
+	if got, want := err.Error(), "2 attempts failed (context canceled): EOF"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// fn is not called once the context is done.
+	fn, calls = attempts()
+	err = Do(ctx, fn, p)
+	if *calls != 0 {
+		t.Errorf("calls: got %d, want 0", *calls)
+	}
+	if got, want := err.Error(), "0 attempts failed (context canceled)"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestDelay(t *testing.T) {
+	tests := []struct {
+		p       Policy
+		attempt int
+		want    time.Duration
+	}{
+		{Policy{InitialDelay: time.Second}, 5, time.Second},
+		{Policy{InitialDelay: time.Second, Multiplier: 0.5}, 5, time.Second},
+		{Policy{InitialDelay: time.Second, Multiplier: 3}, 3, 9 * time.Second},
+		{Policy{InitialDelay: time.Second, Multiplier: 3, MaxDelay: 5 * time.Second}, 3, 5 * time.Second},
+		{Policy{InitialDelay: time.Second, Multiplier: 2}, 1000, 1<<63 - 1},
+		{Policy{InitialDelay: time.Second, Jitter: 0.5, Rand: func() float64 { return 0 }}, 1, time.Second / 2},
+		{Policy{InitialDelay: time.Second, Jitter: 0.5, Rand: func() float64 { return 0.75 }}, 1, time.Second * 5 / 4},
+		{Policy{InitialDelay: time.Second, Jitter: 2, Rand: func() float64 { return 0 }}, 1, 0},
+	}
+
+	for i, tt := range tests {
+		if got := tt.p.delay(tt.attempt); got != tt.want {
+			t.Errorf("test %d: delay(%d): got %v, want %v", i+1, tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestErrorClass(t *testing.T) {
+	p := policy
+	p.Clock = new(fakeClock)
+	tests := []struct {
+		errs []error
+		want classify.Class
+	}{
+		{[]error{classify.Retryable(io.EOF)}, classify.RetryableClass},
+		{[]error{classify.Retryable(io.EOF), classify.Permanent(io.EOF)}, classify.PermanentClass},
+	}
+
+	for i, tt := range tests {
+		fn, _ := attempts(append(tt.errs, tt.errs[len(tt.errs)-1], tt.errs[len(tt.errs)-1], tt.errs[len(tt.errs)-1])...)
+		err := Do(context.Background(), fn, p)
+		if got := classify.Of(err); got != tt.want {
+			t.Errorf("test %d: Of(%v): got %v, want %v", i+1, err, got, tt.want)
+		}
+	}
+}
+
+func TestErrorFormat(t *testing.T) {
+	p := policy
+	p.Clock = new(fakeClock)
+	p.MaxAttempts = 2
+	err := Do(context.Background(), func() error {
+		return errors.New("refused")
+	}, p)
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"%s", "2 attempts failed: refused"},
+		{"%v", "2 attempts failed: refused"},
+		{"%q", `"2 attempts failed: refused"`},
+		{"%+v", "2 attempts failed: refused\n" +
+			"attempt 1: refused\n" +
+			"github.com/pkg/errors/retry.TestErrorFormat.func1\n" +
+			"\t.+/github.com/pkg/errors/retry/retry_test.go:\\d+\n" +
+			"(?s:.*)" +
+			"attempt 2: refused\n" +
+			"github.com/pkg/errors/retry.TestErrorFormat.func1\n"},
+	}
+
+	for i, tt := range tests {
+		got := fmt.Sprintf(tt.format, err)
+		if tt.format != "%+v" {
+			if got != tt.want {
+				t.Errorf("test %d: Sprintf(%q, err):\n got: %q\nwant: %q", i+1, tt.format, got, tt.want)
+			}
+			continue
+		}
+		if !regexp.MustCompile("^"+tt.want).MatchString(got) || strings.Count(got, "attempt ") != 2 {
+			t.Errorf("test %d: Sprintf(%q, err):\n got: %q\nwant: %q", i+1, tt.format, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package errors
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity is how serious an error is, used to choose the level at which
+// it is logged or whether it raises an alert. Severities are ordered from
+// least to most serious; the zero Severity means none was attached.
+type Severity int
+
+const (
+	SeverityDebug    Severity = iota + 1 // of interest only when debugging
+	SeverityInfo                         // expected, such as a client's bad request
+	SeverityWarn                         // unexpected, but handled
+	SeverityError                        // the operation failed
+	SeverityCritical                     // the program cannot continue correctly
+)
+
+func (s Severity) String() string {
+	switch s {
+	case 0:
+		return "none"
+	case SeverityDebug:
+		return "debug"
+	case SeverityInfo:
+		return "info"
+	case SeverityWarn:
+		return "warn"
+	case SeverityError:
+		return "error"
+	case SeverityCritical:
+		return "critical"
+	}
+	return "unknown"
+}
+
+// WithSeverity annotates err with a Severity. The severity does not appear
+// in err's message; %+v prints it in brackets after the message beneath
+// it, and JSONFormatter as the "severity" of that message.
+// If err is nil, WithSeverity returns nil.
+func WithSeverity(err error, severity Severity) error {
+	if err == nil {
+		return nil
+	}
+	return &withSeverity{
+		cause:    err,
+		severity: severity,
+	}
+}
+
+type withSeverity struct {
+	cause    error
+	severity Severity
+}
+
+func (w *withSeverity) Error() string    { return w.cause.Error() }
+func (w *withSeverity) Cause() error     { return w.cause }
+func (w *withSeverity) Annotated() error { return w.cause }
+
+// Unwrap provides compatibility for Go 1.13 error chains.
+func (w *withSeverity) Unwrap() error { return w.cause }
+
+func (w *withSeverity) Format(s fmt.State, verb rune) {
+	currentFormatter().FormatError(s, verb, w)
+}
+
+// MaxSeverity returns the greatest Severity attached to err or any error
+// in its tree, or the zero Severity if there is none.
+func MaxSeverity(err error) Severity {
+	var max Severity
+	Layers(err)(func(l Layer) bool {
+		if w, ok := l.Err.(*withSeverity); ok && w.severity > max {
+			max = w.severity
+		}
+		return true
+	})
+	return max
+}
+
+// severities returns the Severity with which %+v labels each error in
+// errs, a chain as returned by chain: for an error that adds a message,
+// the greatest attached above it and beneath the message above it.
+func severities(errs []error) []Severity {
+	sevs := make([]Severity, len(errs))
+	var pending Severity
+	for i, err := range errs {
+		switch e := err.(type) {
+		case *withSeverity:
+			if e.severity > pending {
+				pending = e.severity
+			}
+		case *withStack, Annotation:
+		default:
+			sevs[i], pending = pending, 0
+		}
+	}
+	return sevs
+}
+
+// labelled returns msg followed by severity, if it is not zero, and by
+// offset, if it is not empty, as printed by %+v.
+func labelled(msg string, severity Severity, offset string) string {
+	var labels []string
+	if msg != "" {
+		labels = append(labels, msg)
+	}
+	if severity != 0 {
+		labels = append(labels, "["+severity.String()+"]")
+	}
+	if offset != "" {
+		labels = append(labels, "("+offset+")")
+	}
+	return strings.Join(labels, " ")
+}
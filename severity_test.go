@@ -0,0 +1,98 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"testing"
+)
+
+func TestWithSeverityNil(t *testing.T) {
+	if got := WithSeverity(nil, SeverityWarn); got != nil {
+		t.Errorf("WithSeverity(nil, SeverityWarn): got %#v, expected nil", got)
+	}
+}
+
+func TestMaxSeverity(t *testing.T) {
+	tests := []struct {
+		err  error
+		want Severity
+	}{
+		{nil, 0},
+		{io.EOF, 0},
+		{WithSeverity(io.EOF, SeverityInfo), SeverityInfo},
+		{Wrap(WithSeverity(io.EOF, SeverityCritical), "read"), SeverityCritical},
+		{WithSeverity(Wrap(WithSeverity(io.EOF, SeverityDebug), "read"), SeverityWarn), SeverityWarn},
+		{WithSeverity(Wrap(WithSeverity(io.EOF, SeverityError), "read"), SeverityWarn), SeverityError},
+		{multiError{WithSeverity(io.EOF, SeverityInfo), WithMessage(WithSeverity(io.EOF, SeverityError), "x")}, SeverityError},
+	}
+
+	for i, tt := range tests {
+		if got := MaxSeverity(tt.err); got != tt.want {
+			t.Errorf("test %d: MaxSeverity(%v): got %v, want %v", i+1, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestSeverityString(t *testing.T) {
+	tests := []struct {
+		s    Severity
+		want string
+	}{
+		{0, "none"},
+		{SeverityDebug, "debug"},
+		{SeverityInfo, "info"},
+		{SeverityWarn, "warn"},
+		{SeverityError, "error"},
+		{SeverityCritical, "critical"},
+		{SeverityCritical + 1, "unknown"},
+	}
+
+	for i, tt := range tests {
+		if got := tt.s.String(); got != tt.want {
+			t.Errorf("test %d: got %q, want %q", i+1, got, tt.want)
+		}
+	}
+}
+
+func TestFormatWithSeverity(t *testing.T) {
+	err := WithSeverity(WithMessage(WithSeverity(New("error"), SeverityInfo), "outer"), SeverityWarn)
+	tests := []struct {
+		f    Formatter
+		want string
+	}{{
+		TextFormatter{},
+		"^error \\[info\\]\n" +
+			"github.com/pkg/errors.TestFormatWithSeverity\n" +
+			"\t.+/github.com/pkg/errors/severity_test.go:59\n" +
+			"(?s:.*)" +
+			"\nouter \\[warn\\]$",
+	}, {
+		TextFormatter{Order: OutermostFirst},
+		"^outer \\[warn\\]\n" +
+			"error \\[info\\]\n" +
+			"github.com/pkg/errors.TestFormatWithSeverity\n",
+	}, {
+		CompactFormatter{},
+		"^outer \\[warn\\]: error \\[info\\] \\(severity_test.go:59\\)$",
+	}, {
+		JSONFormatter{},
+		`^{"message":"outer","severity":"warn"}` + "\n" +
+			`{"message":"error","severity":"info","stack":\[.+\]}$`,
+	}, {
+		TreeFormatter{},
+		"^outer \\[warn\\]\n" +
+			"`- error \\[info\\]$",
+	}}
+
+	for i, tt := range tests {
+		got := fmt.Sprintf("%+v", Format(err, tt.f))
+		if !regexp.MustCompile(tt.want).MatchString(got) {
+			t.Errorf("test %d: %T:\n got: %q\nwant: %q", i+1, tt.f, got, tt.want)
+		}
+	}
+
+	if got, want := err.Error(), "outer: error"; got != want {
+		t.Errorf("Error(): got %q, want %q", got, want)
+	}
+}
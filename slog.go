@@ -0,0 +1,51 @@
+//go:build go1.21
+// +build go1.21
+
+package errors
+
+import "log/slog"
+
+// Level returns the slog.Level at which to log an error of Severity s.
+// SeverityCritical is above slog.LevelError, by the same step as between
+// the levels of slog, and the zero Severity, of an error with none
+// attached, is slog.LevelError.
+func (s Severity) Level() slog.Level {
+	switch s {
+	case SeverityDebug:
+		return slog.LevelDebug
+	case SeverityInfo:
+		return slog.LevelInfo
+	case SeverityWarn:
+		return slog.LevelWarn
+	case SeverityCritical:
+		return slog.LevelError + 4
+	}
+	return slog.LevelError
+}
+
+// LogValue implements slog.LogValuer. It logs an error annotated with
+// WithSeverity as a group of its message, "msg", and the greatest Severity
+// in its tree, "severity". The other errors of this package are logged as
+// errors, so that a handler can format them with %+v; log them with
+// LogValue to include a Severity attached beneath them.
+func (w *withSeverity) LogValue() slog.Value {
+	return LogValue(w)
+}
+
+// LogValue returns the value with which to log err with log/slog: a group
+// of its message, "msg", and, if one is attached, the greatest Severity in
+// its tree, "severity". LogValue(nil) is the value of nil. A log adapter
+// can choose the level for err with MaxSeverity:
+//
+//	logger.LogAttrs(ctx, errors.MaxSeverity(err).Level(), "request failed",
+//		slog.Any("err", errors.LogValue(err)))
+func LogValue(err error) slog.Value {
+	if err == nil {
+		return slog.AnyValue(nil)
+	}
+	attrs := []slog.Attr{slog.String("msg", err.Error())}
+	if s := MaxSeverity(err); s != 0 {
+		attrs = append(attrs, slog.String("severity", s.String()))
+	}
+	return slog.GroupValue(attrs...)
+}
@@ -0,0 +1,94 @@
+//go:build go1.21
+// +build go1.21
+
+package errors
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"testing"
+)
+
+func TestSeverityLevel(t *testing.T) {
+	tests := []struct {
+		s    Severity
+		want slog.Level
+	}{
+		{0, slog.LevelError},
+		{SeverityDebug, slog.LevelDebug},
+		{SeverityInfo, slog.LevelInfo},
+		{SeverityWarn, slog.LevelWarn},
+		{SeverityError, slog.LevelError},
+		{SeverityCritical, slog.LevelError + 4},
+	}
+
+	for i, tt := range tests {
+		if got := tt.s.Level(); got != tt.want {
+			t.Errorf("test %d: %v.Level(): got %v, want %v", i+1, tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestLogValue(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{LogValue(io.EOF), `{"err":{"msg":"EOF"}}`},
+		{LogValue(nil), `{"err":null}`},
+		{LogValue(Wrap(WithSeverity(io.EOF, SeverityWarn), "read")), `{"err":{"msg":"read: EOF","severity":"warn"}}`},
+		{LogValue(E(Op("load"), WithSeverity(io.EOF, SeverityCritical))), `{"err":{"msg":"load: EOF","severity":"critical"}}`},
+		{WithSeverity(WithMessage(io.EOF, "read"), SeverityInfo), `{"err":{"msg":"read: EOF","severity":"info"}}`},
+		{Wrap(io.EOF, "read"), `{"err":"read: EOF"}`},
+	}
+
+	for i, tt := range tests {
+		var b bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&b, &slog.HandlerOptions{
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				if len(groups) == 0 && a.Key != "err" {
+					return slog.Attr{}
+				}
+				return a
+			},
+		}))
+		logger.Info("failed", "err", tt.value)
+		if got := b.String(); got != tt.want+"\n" {
+			t.Errorf("test %d: got %q, want %q", i+1, got, tt.want)
+		}
+	}
+}
+
+func TestLogError(t *testing.T) {
+	// A handler receives the errors of this package, other than those
+	// annotated with WithSeverity, as errors.
+	var got []interface{}
+	logger := slog.New(slog.NewJSONHandler(io.Discard, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == "err" {
+				got = append(got, a.Value.Any())
+			}
+			return a
+		},
+	}))
+	errs := []error{
+		New("boom"),
+		Wrap(io.EOF, "read"),
+		WithMessage(io.EOF, "read"),
+		WithStack(io.EOF),
+		E(Op("load"), io.EOF),
+		WithHint(io.EOF, "retry"),
+	}
+	for _, err := range errs {
+		logger.Error("failed", "err", err)
+	}
+	for i, v := range got {
+		if _, ok := v.(error); !ok {
+			t.Errorf("test %d: got %T, want an error", i+1, v)
+		}
+	}
+	if len(got) != len(errs) {
+		t.Errorf("got %d errors, want %d", len(got), len(errs))
+	}
+}
@@ -0,0 +1,68 @@
+package errors
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// sourceContext is the number of lines printed either side of a Frame's
+// line when source context is requested.
+const sourceContext = 2
+
+// sources caches the lines of source files read while formatting frames.
+// A nil entry records a file that could not be read.
+var sources = struct {
+	sync.Mutex
+	files map[string][]string
+}{
+	files: make(map[string][]string),
+}
+
+// sourceLines returns the lines of file, or nil if it cannot be read.
+func sourceLines(file string) []string {
+	sources.Lock()
+	defer sources.Unlock()
+	lines, ok := sources.files[file]
+	if !ok {
+		if b, err := ioutil.ReadFile(file); err == nil {
+			lines = strings.Split(string(bytes.TrimRight(b, "\n")), "\n")
+		}
+		sources.files[file] = lines
+	}
+	return lines
+}
+
+// formatSource writes the lines of source code surrounding f to w, each
+// preceded by indent, marking f's own line with '>'. Nothing is written if
+// the source is unavailable or f was made by NewFrame or UnmarshalText,
+// whose file, perhaps from another machine, must not be read from this one.
+func (f Frame) formatSource(w io.Writer, indent string) {
+	if _, ok := f.location(); ok {
+		return
+	}
+	line := f.line()
+	lines := sourceLines(f.file())
+	if line < 1 || line > len(lines) {
+		return
+	}
+	first, last := line-sourceContext, line+sourceContext
+	if first < 1 {
+		first = 1
+	}
+	if last > len(lines) {
+		last = len(lines)
+	}
+	width := len(strconv.Itoa(last))
+	for n := first; n <= last; n++ {
+		marker := "  "
+		if n == line {
+			marker = "> "
+		}
+		fmt.Fprintf(w, "\n%s%s%*d | %s", indent, marker, width, n, strings.TrimRight(lines[n-1], " \t\r"))
+	}
+}
@@ -0,0 +1,107 @@
+package errors
+
+import (
+	"testing"
+)
+
+func sourceFrame() Frame {
+	return caller() // this line is printed
+}
+
+func sourceError() error {
+	return WithStack(errFake) // this line is printed
+}
+
+func TestFrameFormatSource(t *testing.T) {
+	tests := []struct {
+		Frame
+		format string
+		want   string
+	}{{
+		sourceFrame(),
+		"%+#v",
+		"github.com/pkg/errors.sourceFrame\n" +
+			"\t.+/github.com/pkg/errors/source_test.go:8\n" +
+			"\t   6 \\| \n" +
+			"\t   7 \\| func sourceFrame\\(\\) Frame {\n" +
+			"\t>  8 \\| \treturn caller\\(\\) // this line is printed\n" +
+			"\t   9 \\| }\n" +
+			"\t  10 \\| $",
+	}, {
+		sourceFrame(),
+		"%+v",
+		"github.com/pkg/errors.sourceFrame\n" +
+			"\t.+/github.com/pkg/errors/source_test.go:8$",
+	}, {
+		sourceFrame(),
+		"%#v",
+		"^source_test.go:8$",
+	}, {
+		0,
+		"%+#v",
+		"unknown\n" +
+			"\tunknown:0$",
+	}}
+
+	for i, tt := range tests {
+		testFormatRegexp(t, i, tt.Frame, tt.format, tt.want)
+	}
+}
+
+func TestFormatSource(t *testing.T) {
+	tests := []struct {
+		error
+		format string
+		want   string
+	}{{
+		sourceError(),
+		"%+#v",
+		"^fake\n" +
+			"github.com/pkg/errors.sourceError\n" +
+			"\t.+/github.com/pkg/errors/source_test.go:12\n" +
+			"\t  10 \\| \n" +
+			"\t  11 \\| func sourceError\\(\\) error {\n" +
+			"\t> 12 \\| \treturn WithStack\\(errFake\\) // this line is printed\n" +
+			"\t  13 \\| }\n" +
+			"\t  14 \\| $",
+	}, {
+		WithMessage(sourceError(), "message"),
+		"%+#v",
+		"^fake\n" +
+			"github.com/pkg/errors.sourceError\n" +
+			"\t.+/github.com/pkg/errors/source_test.go:12\n" +
+			"\t  10 \\| \n" +
+			"\t  11 \\| func sourceError\\(\\) error {\n" +
+			"\t> 12 \\| \treturn WithStack\\(errFake\\) // this line is printed\n",
+	}, {
+		WithMessage(errFake, "message"),
+		"%+#v",
+		"^fake\n" +
+			"message$",
+	}}
+
+	for i, tt := range tests {
+		testFormatRegexp(t, i, tt.error, tt.format, tt.want)
+	}
+}
+
+// errFake is an error not created by this package. It must be printed
+// with %+v even when source context is requested.
+var errFake = fakeError{}
+
+type fakeError struct{}
+
+func (fakeError) Error() string { return "fake" }
+
+func TestSourceLinesMissing(t *testing.T) {
+	const file = "/does/not/exist.go"
+	if got := sourceLines(file); got != nil {
+		t.Errorf("sourceLines(%q): got %q, want nil", file, got)
+	}
+	sources.Lock()
+	_, ok := sources.files[file]
+	sources.Unlock()
+	if !ok {
+		t.Errorf("sourceLines(%q): result not cached", file)
+	}
+}
@@ -12,6 +12,7 @@ import (
 // Frame represents a program counter inside a stack frame.
 // For historical reasons if Frame is interpreted as a uintptr
 // its value represents the program counter + 1.
+// A Frame made by NewFrame instead stands for the location it was given.
 type Frame uintptr
 
 // pc returns the program counter for this frame;
@@ -21,6 +22,9 @@ func (f Frame) pc() uintptr { return uintptr(f) - 1 }
 // file returns the full path to the file that contains the
 // function for this Frame's pc.
 func (f Frame) file() string {
+	if loc, ok := f.location(); ok {
+		return loc.file
+	}
 	fn := runtime.FuncForPC(f.pc())
 	if fn == nil {
 		return "unknown"
@@ -32,6 +36,9 @@ func (f Frame) file() string {
 // line returns the line number of source code of the
 // function for this Frame's pc.
 func (f Frame) line() int {
+	if loc, ok := f.location(); ok {
+		return loc.line
+	}
 	fn := runtime.FuncForPC(f.pc())
 	if fn == nil {
 		return 0
@@ -42,6 +49,9 @@ func (f Frame) line() int {
 
 // name returns the name of this function, if known.
 func (f Frame) name() string {
+	if loc, ok := f.location(); ok {
+		return loc.function
+	}
 	fn := runtime.FuncForPC(f.pc())
 	if fn == nil {
 		return "unknown"
@@ -61,13 +71,20 @@ func (f Frame) name() string {
 //    %+s   function name and path of source file relative to the compile time
 //          GOPATH separated by \n\t (<funcname>\n\t<path>)
 //    %+v   equivalent to %+s:%d
+//    %+#v  equivalent to %+v, followed by the lines of source code
+//          surrounding the Frame, if the source file can be read and
+//          the Frame was not made by NewFrame or UnmarshalText
+//
+// A width given with the + flag, as in %+4v, replaces the tab before the
+// path of the source file with that many spaces.
 func (f Frame) Format(s fmt.State, verb rune) {
 	switch verb {
 	case 's':
 		switch {
 		case s.Flag('+'):
 			io.WriteString(s, f.name())
-			io.WriteString(s, "\n\t")
+			io.WriteString(s, "\n")
+			io.WriteString(s, indent(s))
 			io.WriteString(s, f.file())
 		default:
 			io.WriteString(s, path.Base(f.file()))
@@ -80,6 +97,9 @@ func (f Frame) Format(s fmt.State, verb rune) {
 		f.Format(s, 's')
 		io.WriteString(s, ":")
 		f.Format(s, 'd')
+		if s.Flag('+') && s.Flag('#') {
+			f.formatSource(s, indent(s))
+		}
 	}
 }
 
@@ -104,7 +124,15 @@ type StackTrace []Frame
 // Format accepts flags that alter the printing of some verbs, as follows:
 //
 //    %+v   Prints filename, function, and line number for each Frame in the stack.
+//    %+#v  As %+v, with the source code surrounding each Frame.
+//
+// A precision, as in %+.5v, limits the output to that many of the innermost
+// Frames. A width given with the + flag sets the indentation of each Frame
+// as described by Frame.Format.
 func (st StackTrace) Format(s fmt.State, verb rune) {
+	if verb == 's' || verb == 'v' && (s.Flag('+') || !s.Flag('#')) {
+		st = st[:depth(s, len(st))]
+	}
 	switch verb {
 	case 'v':
 		switch {
@@ -144,9 +172,9 @@ func (s *stack) Format(st fmt.State, verb rune) {
 	case 'v':
 		switch {
 		case st.Flag('+'):
-			for _, pc := range *s {
-				f := Frame(pc)
-				fmt.Fprintf(st, "\n%+v", f)
+			for _, pc := range (*s)[:depth(st, len(*s))] {
+				io.WriteString(st, "\n")
+				Frame(pc).Format(st, verb)
 			}
 		}
 	}
@@ -168,6 +196,25 @@ func callers() *stack {
 	return &st
 }
 
+// depth returns how many of a stack's n frames should be printed for s:
+// all of them, or at most s's precision if one was given.
+func depth(s fmt.State, n int) int {
+	if p, ok := s.Precision(); ok && p < n {
+		return p
+	}
+	return n
+}
+
+// indent returns the indentation printed before the source file of a
+// Frame in the extended format: a tab, or s's width in spaces if one was
+// given with the + flag.
+func indent(s fmt.State) string {
+	if w, ok := s.Width(); ok && s.Flag('+') {
+		return strings.Repeat(" ", w)
+	}
+	return "\t"
+}
+
 // funcname removes the path prefix component of a function's name reported by func.Name().
 func funcname(name string) string {
 	i := strings.LastIndex(name, "/")
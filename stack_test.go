@@ -248,3 +248,53 @@ func caller() Frame {
 	frame, _ := frames.Next()
 	return Frame(frame.PC)
 }
+
+func TestStackTraceFormatDepth(t *testing.T) {
+	tests := []struct {
+		StackTrace
+		format string
+		want   string
+	}{{
+		stackTrace()[:2],
+		"%+.1v",
+		"\n" +
+			"github.com/pkg/errors.stackTrace\n" +
+			"\t.+/github.com/pkg/errors/stack_test.go:174$",
+	}, {
+		stackTrace()[:2],
+		"%+2.1v",
+		"\n" +
+			"github.com/pkg/errors.stackTrace\n" +
+			"  .+/github.com/pkg/errors/stack_test.go:174$",
+	}, {
+		stackTrace()[:2],
+		"%.1v",
+		`^\[stack_test.go:174\]$`,
+	}, {
+		stackTrace()[:2],
+		"%.0s",
+		`^\[\]$`,
+	}, {
+		stackTrace()[:2],
+		"%.1s",
+		`^\[stack_test.go\]$`,
+	}, {
+		stackTrace()[:2],
+		"%+.5v",
+		"\n" +
+			"github.com/pkg/errors.stackTrace\n" +
+			"\t.+/github.com/pkg/errors/stack_test.go:174\n" +
+			"github.com/pkg/errors.TestStackTraceFormatDepth\n" +
+			"\t.+/github.com/pkg/errors/stack_test.go:282$",
+	}}
+
+	for i, tt := range tests {
+		testFormatRegexp(t, i, tt.StackTrace, tt.format, tt.want)
+	}
+}
+
+func TestFrameFormatIndent(t *testing.T) {
+	testFormatRegexp(t, 0, initpc, "%+4v", "github.com/pkg/errors.init\n"+
+		"    .+/github.com/pkg/errors/stack_test.go:9$")
+	testFormatRegexp(t, 1, initpc, "%4v", "^stack_test.go:9$")
+}
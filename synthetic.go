@@ -0,0 +1,120 @@
+package errors
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// location is the function, file and line of a Frame made by NewFrame.
+type location struct {
+	function string
+	file     string
+	line     int
+}
+
+// synthetics holds the locations of the Frames made by NewFrame. The Frame
+// for locations[i] is ^uintptr(0) - i, from the top of the address space
+// where no program counter is found, so that it cannot be mistaken for
+// one. Each location is held once, however many times it is made, and at
+// most maxSynthetics are held.
+var synthetics = struct {
+	sync.RWMutex
+	locations []location
+	frames    map[location]Frame
+}{
+	frames: make(map[location]Frame),
+}
+
+// maxSynthetics bounds the locations held for Frames made by NewFrame, so
+// that decoding stack traces from untrusted input cannot grow them without
+// limit.
+var maxSynthetics = 1 << 16
+
+// syntheticCount is len(synthetics.locations), read without the lock so
+// that the Frames of the running program are told apart cheaply.
+var syntheticCount uint32
+
+// NewFrame returns a Frame for a call in function at the given file and
+// line, which need not be in the running program. Such a Frame formats as
+// one recorded by New or Wrap with the same location would, so a
+// StackTrace can be built for a test fixture or decoded from elsewhere:
+//
+//	st := errors.StackTrace{
+//		errors.NewFrame("main.load", "/src/app/main.go", 42),
+//		errors.NewFrame("main.main", "/src/app/main.go", 12),
+//	}
+//	err = errors.WithStackTrace(err, st)
+//
+// An empty function or file is printed as "unknown". Every Frame made for
+// a location is recorded for the life of the program. Once 65536 distinct
+// locations have been recorded, NewFrame returns the zero Frame, which is
+// printed as "unknown", for any other.
+func NewFrame(function, file string, line int) Frame {
+	if function == "" {
+		function = "unknown"
+	}
+	if file == "" {
+		file = "unknown"
+	}
+	loc := location{function, file, line}
+
+	synthetics.RLock()
+	f, ok := synthetics.frames[loc]
+	synthetics.RUnlock()
+	if ok {
+		return f
+	}
+
+	synthetics.Lock()
+	defer synthetics.Unlock()
+	if f, ok := synthetics.frames[loc]; ok {
+		return f
+	}
+	if len(synthetics.locations) >= maxSynthetics {
+		return 0
+	}
+	f = Frame(^uintptr(0) - uintptr(len(synthetics.locations)))
+	synthetics.locations = append(synthetics.locations, loc)
+	synthetics.frames[loc] = f
+	atomic.StoreUint32(&syntheticCount, uint32(len(synthetics.locations)))
+	return f
+}
+
+// location returns the location of f if it was made by NewFrame.
+func (f Frame) location() (location, bool) {
+	i := ^uintptr(0) - uintptr(f)
+	if i >= uintptr(atomic.LoadUint32(&syntheticCount)) {
+		return location{}, false
+	}
+	synthetics.RLock()
+	defer synthetics.RUnlock()
+	if i >= uintptr(len(synthetics.locations)) {
+		return location{}, false
+	}
+	return synthetics.locations[i], true
+}
+
+// UnmarshalText sets f to the Frame encoded by MarshalText as text. The
+// Frame is made by NewFrame, so it formats as the encoded one did, within
+// the limit on the locations NewFrame records.
+func (f *Frame) UnmarshalText(text []byte) error {
+	s := string(text)
+	if s == "unknown" {
+		*f = 0
+		return nil
+	}
+	i := strings.Index(s, " ")
+	j := strings.LastIndex(s, ":")
+	if i < 0 || j < i {
+		return fmt.Errorf("errors: invalid frame %q", s)
+	}
+	line, err := strconv.Atoi(s[j+1:])
+	if err != nil {
+		return fmt.Errorf("errors: invalid frame %q", s)
+	}
+	*f = NewFrame(s[:i], s[i+1:j], line)
+	return nil
+}
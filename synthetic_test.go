@@ -0,0 +1,151 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewFrameFormat(t *testing.T) {
+	real := initpc
+	synthetic := NewFrame(real.name(), real.file(), real.line())
+	formats := []string{"%s", "%+s", "%d", "%n", "%v", "%+v", "%+4v"}
+
+	for _, format := range formats {
+		want := fmt.Sprintf(format, real)
+		if got := fmt.Sprintf(format, synthetic); got != want {
+			t.Errorf("fmt.Sprintf(%q, NewFrame(...)): got %q, want %q", format, got, want)
+		}
+	}
+}
+
+func TestNewFrame(t *testing.T) {
+	f := NewFrame("main.load", "/src/app/main.go", 42)
+	if g := NewFrame("main.load", "/src/app/main.go", 42); g != f {
+		t.Errorf("NewFrame twice: got %d and %d, want the same Frame", f, g)
+	}
+	if g := NewFrame("main.load", "/src/app/main.go", 43); g == f {
+		t.Errorf("NewFrame for another line: got %d, want another Frame", g)
+	}
+
+	tests := []struct {
+		f      Frame
+		format string
+		want   string
+	}{
+		{f, "%s", "main.go"},
+		{f, "%+s", "main.load\n\t/src/app/main.go"},
+		{f, "%d", "42"},
+		{f, "%n", "load"},
+		{f, "%v", "main.go:42"},
+		{f, "%+v", "main.load\n\t/src/app/main.go:42"},
+		{NewFrame("", "", 0), "%+v", "unknown\n\tunknown:0"},
+	}
+
+	for i, tt := range tests {
+		if got := fmt.Sprintf(tt.format, tt.f); got != tt.want {
+			t.Errorf("test %d: fmt.Sprintf(%q, f): got %q, want %q", i+1, tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestWithStackTrace(t *testing.T) {
+	if got := WithStackTrace(nil, nil); got != nil {
+		t.Errorf("WithStackTrace(nil, nil): got %#v, expected nil", got)
+	}
+
+	st := StackTrace{
+		NewFrame("main.load", "/src/app/main.go", 42),
+		NewFrame("main.main", "/src/app/main.go", 12),
+	}
+	err := WithStackTrace(io.EOF, st)
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"%s", "EOF"},
+		{"%+v", "EOF\nmain.load\n\t/src/app/main.go:42\nmain.main\n\t/src/app/main.go:12"},
+		{"%+.1v", "EOF\nmain.load\n\t/src/app/main.go:42"},
+	}
+
+	for i, tt := range tests {
+		if got := fmt.Sprintf(tt.format, err); got != tt.want {
+			t.Errorf("test %d: fmt.Sprintf(%q, err): got %q, want %q", i+1, tt.format, got, tt.want)
+		}
+	}
+
+	got := err.(interface{ StackTrace() StackTrace }).StackTrace()
+	if !reflect.DeepEqual(got, st) {
+		t.Errorf("StackTrace(): got %v, want %v", got, st)
+	}
+}
+
+func TestFrameUnmarshalText(t *testing.T) {
+	st := StackTrace{initpc, NewFrame("main.main", "/src/my app/main.go", 12), 0}
+	b, err := json.Marshal(st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got StackTrace
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if want := fmt.Sprintf("%+v", st); fmt.Sprintf("%+v", got) != want {
+		t.Errorf("unmarshal %s: got %+v, want %s", b, got, want)
+	}
+
+	for _, text := range []string{"", "main.main", "main.main main.go", "main.main main.go:x"} {
+		var f Frame
+		if err := f.UnmarshalText([]byte(text)); err == nil {
+			t.Errorf("UnmarshalText(%q): got nil error", text)
+		}
+	}
+}
+
+func TestNewFrameLimit(t *testing.T) {
+	defer func(max int) { maxSynthetics = max }(maxSynthetics)
+	synthetics.RLock()
+	maxSynthetics = len(synthetics.locations) + 1
+	synthetics.RUnlock()
+
+	f := NewFrame("main.limit", "/src/app/limit.go", 1)
+	if f == 0 {
+		t.Fatalf("NewFrame below the limit: got the zero Frame")
+	}
+	if g := NewFrame("main.limit", "/src/app/limit.go", 2); g != 0 {
+		t.Errorf("NewFrame beyond the limit: got %d, want the zero Frame", g)
+	}
+	if g := NewFrame("main.limit", "/src/app/limit.go", 1); g != f {
+		t.Errorf("NewFrame for a recorded location beyond the limit: got %d, want %d", g, f)
+	}
+	var g Frame
+	if err := g.UnmarshalText([]byte("main.limit /src/app/limit.go:3")); err != nil || g != 0 {
+		t.Errorf("UnmarshalText beyond the limit: got %d, %v, want the zero Frame", g, err)
+	}
+	if got := fmt.Sprintf("%+v", g); got != "unknown\n\tunknown:0" {
+		t.Errorf("Frame beyond the limit: got %q, want unknown", got)
+	}
+}
+
+func TestFrameSourceUnread(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "secret.txt")
+	if err := ioutil.WriteFile(file, []byte("one\nTOPSECRET\nthree\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var f Frame
+	if err := f.UnmarshalText([]byte("main.main " + filepath.ToSlash(file) + ":2")); err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprintf("%+#v", f); strings.Contains(got, "TOPSECRET") {
+		t.Errorf("%%+#v of a decoded Frame: got %q, want no source", got)
+	}
+	err := WithStackTrace(io.EOF, StackTrace{f})
+	if got := fmt.Sprintf("%+#v", err); strings.Contains(got, "TOPSECRET") {
+		t.Errorf("%%+#v of an error with a decoded stack trace: got %q, want no source", got)
+	}
+}
@@ -0,0 +1,94 @@
+package errors
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// clock holds the function installed with SetClock as a clockValue.
+var clock atomic.Value
+
+// clockValue gives every function stored in clock the same concrete type,
+// as required by atomic.Value, and allows a nil function to be stored.
+type clockValue struct{ now func() time.Time }
+
+// SetClock enables timestamps: New, Errorf, Wrap, Wrapf, WithStack, E and
+// their Lazy variants record the time returned by now when they are
+// called, and %+v and JSONFormatter print, after each message, when it was
+// added relative to the earliest error in the chain, normally the
+// innermost:
+//
+//	errors.SetClock(time.Now)
+//
+// Timestamps are disabled by default, and by a nil now. While they are, an
+// error holds only a nil pointer in place of its time. Tests may install a fake
+// clock to make the times predictable. SetClock is safe to call
+// concurrently with the creation of errors.
+func SetClock(now func() time.Time) {
+	clock.Store(clockValue{now})
+}
+
+// now returns the time from the clock installed with SetClock, or nil if
+// timestamps are disabled.
+func now() *time.Time {
+	if v, ok := clock.Load().(clockValue); ok && v.now != nil {
+		t := v.now()
+		return &t
+	}
+	return nil
+}
+
+// timeOf returns the time at which at points, or the zero Time if it is
+// nil.
+func timeOf(at *time.Time) time.Time {
+	if at == nil {
+		return time.Time{}
+	}
+	return *at
+}
+
+// times returns the time at which each error in errs, a chain as returned
+// by chain, was added, if timestamps were enabled. The time of a stack
+// recorded by Wrap or WithStack belongs to the message beneath it, as in
+// nodes, unless it has none, in which case it stays with the stack.
+func times(errs []error) []time.Time {
+	ts := make([]time.Time, len(errs))
+	pending := -1
+	for i, err := range errs {
+		switch e := err.(type) {
+		case *withStack:
+			ts[i], pending = timeOf(e.at), i
+		case *fundamental:
+			ts[i], pending = timeOf(e.at), -1
+		case *Error:
+			ts[i], pending = timeOf(e.at), -1
+		case Annotation:
+		default:
+			if pending >= 0 {
+				ts[i], ts[pending], pending = ts[pending], time.Time{}, -1
+			}
+		}
+	}
+	return ts
+}
+
+// earliest returns the earliest of ts that is not zero, or the zero Time
+// if they all are.
+func earliest(ts ...time.Time) time.Time {
+	var min time.Time
+	for _, t := range ts {
+		if !t.IsZero() && (min.IsZero() || t.Before(min)) {
+			min = t
+		}
+	}
+	return min
+}
+
+// offset returns at relative to base, as printed by %+v, or "" if at is
+// the zero Time.
+func offset(at, base time.Time) string {
+	if at.IsZero() {
+		return ""
+	}
+	return "+" + at.Sub(base).String()
+}
@@ -0,0 +1,117 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"testing"
+	"time"
+)
+
+// fakeClock returns a clock for SetClock that starts at start and advances
+// by step each time it is read.
+func fakeClock(start time.Time, step time.Duration) func() time.Time {
+	t := start.Add(-step)
+	return func() time.Time {
+		t = t.Add(step)
+		return t
+	}
+}
+
+func TestSetClock(t *testing.T) {
+	defer SetClock(nil)
+	start := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	if err := New("error").(*fundamental); err.at != nil {
+		t.Errorf("New with timestamps disabled: got time %v, want none", *err.at)
+	}
+
+	SetClock(fakeClock(start, time.Millisecond))
+	tests := []struct {
+		err  error
+		want time.Time
+	}{
+		{New("error"), start},
+		{Errorf("error"), start.Add(1 * time.Millisecond)},
+		{WithStack(io.EOF), start.Add(2 * time.Millisecond)},
+		{Wrap(io.EOF, "read"), start.Add(3 * time.Millisecond)},
+		{Wrapf(io.EOF, "read"), start.Add(4 * time.Millisecond)},
+		{LazyErrorf("error"), start.Add(5 * time.Millisecond)},
+		{LazyWrapf(io.EOF, "read"), start.Add(6 * time.Millisecond)},
+		{E(Op("read")), start.Add(7 * time.Millisecond)},
+	}
+
+	for i, tt := range tests {
+		var got time.Time
+		switch e := tt.err.(type) {
+		case *fundamental:
+			got = timeOf(e.at)
+		case *withStack:
+			got = timeOf(e.at)
+		case *Error:
+			got = timeOf(e.at)
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("test %d: got %v, want %v", i+1, got, tt.want)
+		}
+	}
+
+	SetClock(nil)
+	if err := Wrap(io.EOF, "read").(*withStack); err.at != nil {
+		t.Errorf("Wrap after SetClock(nil): got time %v, want none", *err.at)
+	}
+}
+
+func TestFormatTimestamps(t *testing.T) {
+	defer SetClock(nil)
+	SetClock(fakeClock(time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC), 1500*time.Microsecond))
+	err := WithStack(Wrap(WithSeverity(New("error"), SeverityWarn), "outer"))
+
+	tests := []struct {
+		f    Formatter
+		want string
+	}{{
+		TextFormatter{},
+		"^error \\[warn\\] \\(\\+0s\\)\n" +
+			"github.com/pkg/errors.TestFormatTimestamps\n" +
+			"(?s:.*)" +
+			"\nouter \\(\\+1.5ms\\)\n" +
+			"github.com/pkg/errors.TestFormatTimestamps\n" +
+			"(?s:.*)" +
+			"\n\\(\\+3ms\\)\n" +
+			"github.com/pkg/errors.TestFormatTimestamps\n",
+	}, {
+		TextFormatter{Order: OutermostFirst},
+		"^\\(\\+3ms\\)\n" +
+			"github.com/pkg/errors.TestFormatTimestamps\n" +
+			"(?s:.*)" +
+			"\nouter \\(\\+1.5ms\\)\n" +
+			"github.com/pkg/errors.TestFormatTimestamps\n" +
+			"(?s:.*)" +
+			"\nerror \\[warn\\] \\(\\+0s\\)\n" +
+			"github.com/pkg/errors.TestFormatTimestamps\n",
+	}, {
+		CompactFormatter{},
+		"^\\(\\+3ms\\) \\(timestamp_test.go:\\d+\\): outer \\(\\+1.5ms\\) \\(timestamp_test.go:\\d+\\): error \\[warn\\] \\(\\+0s\\) \\(timestamp_test.go:\\d+\\)$",
+	}, {
+		JSONFormatter{},
+		`^{"message":"","time":"2026-10-18T12:00:00.003Z","offset":"\+3ms","stack":\[.+\]}` + "\n" +
+			`{"message":"outer","time":"2026-10-18T12:00:00.0015Z","offset":"\+1.5ms","stack":\[.+\]}` + "\n" +
+			`{"message":"error","severity":"warn","time":"2026-10-18T12:00:00Z","offset":"\+0s","stack":\[.+\]}$`,
+	}, {
+		TreeFormatter{},
+		"^outer \\(\\+1.5ms\\)\n" +
+			"`- error \\[warn\\] \\(\\+0s\\)$",
+	}}
+
+	for i, tt := range tests {
+		got := fmt.Sprintf("%+v", Format(err, tt.f))
+		if !regexp.MustCompile(tt.want).MatchString(got) {
+			t.Errorf("test %d: %T:\n got: %q\nwant: %q", i+1, tt.f, got, tt.want)
+		}
+	}
+
+	if got, want := err.Error(), "outer: error"; got != want {
+		t.Errorf("Error(): got %q, want %q", got, want)
+	}
+}
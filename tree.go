@@ -0,0 +1,188 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// TreeFormatter is a Formatter whose %+v draws an error as an indented
+// tree with the outermost message at its root:
+//
+//	outer
+//	`- 2 errors
+//	   |- first
+//	   `- second
+//	      `- cause
+//
+// Beneath the errors created by this package, the tree follows any error
+// with an Unwrap() []error, Unwrap() error or Cause() error method, so it
+// can draw errors from elsewhere, including those joined with errors.Join.
+// Where such an error's message repeats those of its causes, only the
+// remainder is drawn; a joined error with no message of its own is drawn
+// as the number of errors it joins.
+//
+// If Stacks is set, the stack recorded with each message is drawn
+// beneath it, limited by any precision as in StackTrace.Format.
+type TreeFormatter struct {
+	Stacks bool
+}
+
+// treeNode is a node of the tree drawn by TreeFormatter.
+type treeNode struct {
+	node
+	children []*treeNode
+}
+
+// FormatError implements Formatter.
+func (t TreeFormatter) FormatError(s fmt.State, verb rune, err error) {
+	if verb != 'v' || !s.Flag('+') {
+		formatMessage(s, verb, err, Colors{})
+		return
+	}
+	root := buildTree(err)
+	if vs := t.visible([]*treeNode{root}); len(vs) == 1 {
+		root = vs[0]
+	}
+	t.writeNode(s, s, root, root.earliest(), "", true, true)
+}
+
+// buildTree returns the root of the tree of err's messages.
+func buildTree(err error) *treeNode {
+	var root, parent *treeNode
+	for _, n := range nodes(err) {
+		tn := &treeNode{node: n}
+		if parent == nil {
+			root = tn
+		} else {
+			parent.children = append(parent.children, tn)
+		}
+		parent = tn
+	}
+
+	// nodes stops at the first error not created by this package, which
+	// may have causes of its own.
+	switch parent.err.(type) {
+	case *fundamental, *withStack, *withMessage, *Error:
+		return root
+	}
+	cs := causes(parent.err)
+	for _, c := range cs {
+		parent.children = append(parent.children, buildTree(c))
+	}
+	parent.msg = ownMessage(parent.err, cs)
+	if _, ok := parent.err.(interface{ Unwrap() []error }); ok && parent.msg == "" {
+		parent.msg = fmt.Sprintf("%d errors", len(cs))
+		if len(cs) == 1 {
+			parent.msg = "1 error"
+		}
+	}
+	return root
+}
+
+// causes returns the errors wrapped by err, found with its Unwrap() []error,
+// Unwrap() error or Cause() error method, in that order of preference.
+func causes(err error) []error {
+	var cs []error
+	switch e := err.(type) {
+	case interface{ Unwrap() []error }:
+		for _, c := range e.Unwrap() {
+			if c != nil {
+				cs = append(cs, c)
+			}
+		}
+	case interface{ Unwrap() error }:
+		if c := e.Unwrap(); c != nil {
+			cs = append(cs, c)
+		}
+	case interface{ Cause() error }:
+		if c := e.Cause(); c != nil {
+			cs = append(cs, c)
+		}
+	}
+	return cs
+}
+
+// ownMessage returns the part of err's message that is not repeated from
+// the messages of cs, the errors it wraps. An error whose message is just
+// those of its causes, such as one made by errors.Join, has no message of
+// its own.
+func ownMessage(err error, cs []error) string {
+	msg := err.Error()
+	if _, ok := err.(interface{ Unwrap() []error }); ok {
+		msgs := make([]string, len(cs))
+		for i, c := range cs {
+			msgs[i] = c.Error()
+		}
+		if msg == strings.Join(msgs, "\n") {
+			return ""
+		}
+		return msg
+	}
+	if len(cs) == 1 {
+		cause := cs[0].Error()
+		if msg == cause {
+			return ""
+		}
+		return strings.TrimSuffix(msg, ": "+cause)
+	}
+	return msg
+}
+
+// earliest returns the earliest time at which n or a node beneath it was
+// added, or the zero Time if timestamps were disabled.
+func (n *treeNode) earliest() time.Time {
+	min := n.at
+	for _, c := range n.children {
+		min = earliest(min, c.earliest())
+	}
+	return min
+}
+
+// writeNode writes n and its children to w, with times relative to base.
+// prefix is written before each line below n's parent; last reports
+// whether n is its parent's last child. The depth and indentation of
+// stacks follow StackTrace.Format for s.
+func (t TreeFormatter) writeNode(w io.Writer, s fmt.State, n *treeNode, base time.Time, prefix string, root, last bool) {
+	if !root {
+		io.WriteString(w, "\n")
+		io.WriteString(w, prefix)
+		if last {
+			io.WriteString(w, "`- ")
+			prefix += "   "
+		} else {
+			io.WriteString(w, "|- ")
+			prefix += "|  "
+		}
+	}
+	io.WriteString(w, n.label(base))
+	children := t.visible(n.children)
+	if t.Stacks && n.stack != nil {
+		bar := "   "
+		if len(children) > 0 {
+			bar = "|  "
+		}
+		for _, f := range frames(s, *n.stack) {
+			file, line := position(s, f)
+			fmt.Fprintf(w, "\n%s%s%s\n%s%s%s%s:%s", prefix, bar, f.name(), prefix, bar, indent(s), file, line)
+		}
+	}
+	for i, c := range children {
+		t.writeNode(w, s, c, base, prefix, false, i == len(children)-1)
+	}
+}
+
+// visible returns the nodes to draw in place of ns. A node with nothing to
+// draw, such as a wrapper that adds no message, is replaced by its children.
+func (t TreeFormatter) visible(ns []*treeNode) []*treeNode {
+	var vs []*treeNode
+	for _, n := range ns {
+		if n.msg == "" && n.severity == 0 && (n.stack == nil || !t.Stacks) {
+			vs = append(vs, t.visible(n.children)...)
+			continue
+		}
+		vs = append(vs, n)
+	}
+	return vs
+}
@@ -0,0 +1,93 @@
+package errors
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestTreeFormatter(t *testing.T) {
+	tests := []struct {
+		TreeFormatter
+		err  error
+		want string
+	}{{
+		TreeFormatter{},
+		io.EOF,
+		"^EOF$",
+	}, {
+		TreeFormatter{},
+		WithMessage(WithMessage(io.EOF, "inner"), "outer"),
+		"^outer\n" +
+			"`- inner\n" +
+			"   `- EOF$",
+	}, {
+		TreeFormatter{Stacks: true},
+		Wrap(WithMessage(io.EOF, "inner"), "outer"),
+		"^outer\n" +
+			"\\|  github.com/pkg/errors.TestTreeFormatter\n" +
+			"\\|  \t.+/github.com/pkg/errors/tree_test.go:26$",
+	}, {
+		TreeFormatter{},
+		WithMessage(multiError{io.EOF, foreignWrapper{"first", io.ErrUnexpectedEOF}}, "outer"),
+		"^outer\n" +
+			"`- 2 errors\n" +
+			"   \\|- EOF\n" +
+			"   `- first\n" +
+			"      `- unexpected EOF$",
+	}, {
+		TreeFormatter{},
+		multiError{Wrap(io.EOF, "first"), multiError{io.ErrClosedPipe, io.ErrShortWrite}},
+		"^2 errors\n" +
+			"\\|- first\n" +
+			"\\|  `- EOF\n" +
+			"`- 2 errors\n" +
+			"   \\|- io: read/write on closed pipe\n" +
+			"   `- short write$",
+	}, {
+		TreeFormatter{},
+		foreignWrapper{"", WithMessage(io.EOF, "inner")},
+		"^inner\n" +
+			"`- EOF$",
+	}, {
+		TreeFormatter{Stacks: true},
+		multiError{WithStack(io.EOF)},
+		"^1 error\n" +
+			"`- EOF\n" +
+			"      github.com/pkg/errors.TestTreeFormatter\n" +
+			"      \t.+/github.com/pkg/errors/tree_test.go:54$",
+	}}
+
+	for i, tt := range tests {
+		testFormatRegexp(t, i, Format(tt.err, tt.TreeFormatter), "%+v", tt.want)
+	}
+}
+
+// multiError is an error tree in the style of errors.Join.
+type multiError []error
+
+func (m multiError) Error() string {
+	msgs := make([]string, len(m))
+	for i, err := range m {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (m multiError) Unwrap() []error { return m }
+
+// foreignWrapper is a wrapper from another package that has only a
+// Cause method.
+type foreignWrapper struct {
+	msg   string
+	cause error
+}
+
+func (w foreignWrapper) Error() string {
+	if w.msg == "" {
+		return w.cause.Error()
+	}
+	return w.msg + ": " + w.cause.Error()
+}
+
+func (w foreignWrapper) Cause() error { return w.cause }